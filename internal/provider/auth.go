@@ -0,0 +1,221 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	cognitosrp "github.com/alexrudd/cognito-srp/v4"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	cip "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	ciptypes "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/devopzilla/guku-client-go"
+)
+
+// Credentials produce the authorization header value sent with every guku
+// API request.
+type Credentials interface {
+	Authorization(ctx context.Context) (string, error)
+}
+
+// APITokenCredentials authenticate with a guku API token, typically issued
+// to a service account.
+type APITokenCredentials struct {
+	Token string
+}
+
+func (c *APITokenCredentials) Authorization(ctx context.Context) (string, error) {
+	return "Bearer " + c.Token, nil
+}
+
+// CognitoConfig identifies the Cognito user pool and app client guku users
+// authenticate against.
+type CognitoConfig struct {
+	Region     string
+	UserPoolID string
+	ClientID   string
+}
+
+// DefaultCognitoConfig is the user pool of the hosted guku service.
+var DefaultCognitoConfig = CognitoConfig{
+	Region:     guku.COGNITO_REGION,
+	UserPoolID: guku.COGNITO_POOL_ID,
+	ClientID:   guku.COGNITO_CLIENT_ID,
+}
+
+// cognitoExpiryMargin is how long before their expiry Cognito tokens are
+// refreshed, so that a request never goes out with a token that expires in
+// flight.
+const cognitoExpiryMargin = time.Minute
+
+// CognitoCredentials authenticate with the id token of a Cognito user. The
+// tokens are refreshed with RefreshToken once they expire.
+type CognitoCredentials struct {
+	IDToken      string    `json:"id_token"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	Expiry       time.Time `json:"expiry"`
+
+	// Cognito is the user pool the tokens were issued by.
+	Cognito CognitoConfig `json:"-"`
+	// HTTPClient is used to talk to Cognito.
+	HTTPClient *http.Client `json:"-"`
+
+	// OnRefresh, if set, is called after the tokens have been refreshed.
+	OnRefresh func(*CognitoCredentials) `json:"-"`
+
+	mu sync.Mutex
+}
+
+func (c *CognitoCredentials) Authorization(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.expired() {
+		if err := c.refresh(ctx); err != nil {
+			return "", err
+		}
+	}
+	return c.IDToken, nil
+}
+
+// Refresh exchanges the refresh token for new tokens if the current ones
+// have expired.
+func (c *CognitoCredentials) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.expired() {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+func (c *CognitoCredentials) expired() bool {
+	return !c.Expiry.IsZero() && time.Now().Add(cognitoExpiryMargin).After(c.Expiry)
+}
+
+func (c *CognitoCredentials) refresh(ctx context.Context) error {
+	if c.RefreshToken == "" {
+		return errors.New("guku session expired and no refresh token is available")
+	}
+
+	svc, err := newCognitoClient(ctx, c.Cognito, c.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	resp, err := svc.InitiateAuth(ctx, &cip.InitiateAuthInput{
+		AuthFlow: ciptypes.AuthFlowTypeRefreshTokenAuth,
+		ClientId: aws.String(c.Cognito.ClientID),
+		AuthParameters: map[string]string{
+			"REFRESH_TOKEN": c.RefreshToken,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to refresh guku session: %w", err)
+	}
+
+	result := resp.AuthenticationResult
+	if result == nil || result.IdToken == nil {
+		return errors.New("unable to refresh guku session: no authentication result returned")
+	}
+
+	c.IDToken = aws.ToString(result.IdToken)
+	c.AccessToken = aws.ToString(result.AccessToken)
+	c.Expiry = tokenExpiry(result.ExpiresIn)
+	// Cognito only rotates the refresh token when rotation is enabled
+	if result.RefreshToken != nil {
+		c.RefreshToken = aws.ToString(result.RefreshToken)
+	}
+
+	if c.OnRefresh != nil {
+		c.OnRefresh(c)
+	}
+	return nil
+}
+
+func tokenExpiry(expiresIn int32) time.Time {
+	return time.Now().Add(time.Duration(expiresIn) * time.Second)
+}
+
+func newCognitoClient(ctx context.Context, cognito CognitoConfig, httpClient *http.Client) (*cip.Client, error) {
+	cfg, err := config.LoadDefaultConfig(
+		ctx,
+		config.WithRegion(cognito.Region),
+		config.WithHTTPClient(httpClient),
+		config.WithCredentialsProvider(aws.AnonymousCredentials{}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return cip.NewFromConfig(cfg), nil
+}
+
+// CognitoRefreshTokenSession exchanges a long-lived refresh token for a new
+// Cognito session.
+func CognitoRefreshTokenSession(ctx context.Context, cognito CognitoConfig, httpClient *http.Client, refreshToken string) (*CognitoCredentials, error) {
+	credentials := &CognitoCredentials{RefreshToken: refreshToken, Cognito: cognito, HTTPClient: httpClient}
+	if err := credentials.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// CognitoLogin performs the Cognito SRP handshake for username and password.
+func CognitoLogin(ctx context.Context, cognito CognitoConfig, httpClient *http.Client, username string, password string) (*CognitoCredentials, error) {
+	csrp, err := cognitosrp.NewCognitoSRP(username, password, cognito.UserPoolID, cognito.ClientID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := newCognitoClient(ctx, cognito, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := svc.InitiateAuth(ctx, &cip.InitiateAuthInput{
+		AuthFlow:       ciptypes.AuthFlowTypeUserSrpAuth,
+		ClientId:       aws.String(csrp.GetClientId()),
+		AuthParameters: csrp.GetAuthParams(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ChallengeName != ciptypes.ChallengeNameTypePasswordVerifier {
+		return nil, fmt.Errorf("unexpected challenge type %s", resp.ChallengeName)
+	}
+
+	challengeResponses, err := csrp.PasswordVerifierChallenge(resp.ChallengeParameters, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := svc.RespondToAuthChallenge(ctx, &cip.RespondToAuthChallengeInput{
+		ChallengeName:      ciptypes.ChallengeNameTypePasswordVerifier,
+		ChallengeResponses: challengeResponses,
+		ClientId:           aws.String(csrp.GetClientId()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := challenge.AuthenticationResult
+	if result == nil || result.IdToken == nil {
+		return nil, errors.New("no authentication result returned")
+	}
+
+	return &CognitoCredentials{
+		IDToken:      aws.ToString(result.IdToken),
+		AccessToken:  aws.ToString(result.AccessToken),
+		RefreshToken: aws.ToString(result.RefreshToken),
+		Expiry:       tokenExpiry(result.ExpiresIn),
+		Cognito:      cognito,
+		HTTPClient:   httpClient,
+	}, nil
+}
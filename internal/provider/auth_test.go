@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCognitoLogin(t *testing.T) {
+	cognito := newFakeCognito(t)
+	cognito.AddUser("alice", "secret")
+
+	credentials, err := CognitoLogin(context.Background(), fakeCognitoConfig, cognito.HTTPClient(), "alice", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	authorization, err := credentials.Authorization(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !cognito.ValidIDToken(authorization) {
+		t.Errorf("expected issued id token, got %q", authorization)
+	}
+	if credentials.RefreshToken == "" || !credentials.Expiry.After(time.Now()) {
+		t.Errorf("expected a refreshable session, got %+v", credentials)
+	}
+}
+
+func TestCognitoLogin_incorrectPassword(t *testing.T) {
+	cognito := newFakeCognito(t)
+	cognito.AddUser("alice", "secret")
+
+	if _, err := CognitoLogin(context.Background(), fakeCognitoConfig, cognito.HTTPClient(), "alice", "wrong"); err == nil {
+		t.Fatal("expected error for incorrect password")
+	}
+	if _, err := CognitoLogin(context.Background(), fakeCognitoConfig, cognito.HTTPClient(), "bob", "secret"); err == nil {
+		t.Fatal("expected error for unknown user")
+	}
+}
+
+func TestCognitoRefreshTokenSession(t *testing.T) {
+	cognito := newFakeCognito(t)
+	cognito.AddRefreshToken("refresh", "alice")
+
+	credentials, err := CognitoRefreshTokenSession(context.Background(), fakeCognitoConfig, cognito.HTTPClient(), "refresh")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		authorization, err := credentials.Authorization(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if authorization != "id-alice-1" {
+			t.Errorf("expected exchanged id token, got %q", authorization)
+		}
+	}
+	if n := cognito.Requests("REFRESH_TOKEN_AUTH"); n != 1 {
+		t.Errorf("expected valid tokens not to be exchanged again, got %d exchanges", n)
+	}
+}
+
+func TestCognitoRefreshTokenSession_rejected(t *testing.T) {
+	cognito := newFakeCognito(t)
+
+	if _, err := CognitoRefreshTokenSession(context.Background(), fakeCognitoConfig, cognito.HTTPClient(), "unknown"); err == nil {
+		t.Fatal("expected error for rejected refresh token")
+	}
+}
+
+func TestCognitoCredentials_expired(t *testing.T) {
+	cognito := newFakeCognito(t)
+	cognito.AddRefreshToken("refresh", "alice")
+	// tokens expiring within cognitoExpiryMargin are exchanged again on
+	// every request
+	cognito.SetExpiresIn(int32((cognitoExpiryMargin / 2).Seconds()))
+
+	credentials, err := CognitoRefreshTokenSession(context.Background(), fakeCognitoConfig, cognito.HTTPClient(), "refresh")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var refreshed []string
+	credentials.OnRefresh = func(c *CognitoCredentials) {
+		refreshed = append(refreshed, c.IDToken)
+	}
+
+	authorization, err := credentials.Authorization(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if authorization != "id-alice-2" {
+		t.Errorf("expected expired tokens to be exchanged again, got %q", authorization)
+	}
+	if len(refreshed) != 1 || refreshed[0] != "id-alice-2" {
+		t.Errorf("expected OnRefresh to be called with the new tokens, got %v", refreshed)
+	}
+	// Cognito did not rotate the refresh token, so it is kept
+	if credentials.RefreshToken != "refresh" {
+		t.Errorf("expected refresh token to be kept, got %q", credentials.RefreshToken)
+	}
+}
+
+func TestCognitoCredentials_expiredWithoutRefreshToken(t *testing.T) {
+	credentials := &CognitoCredentials{
+		IDToken: "expired",
+		Expiry:  time.Now().Add(-time.Hour),
+	}
+
+	if _, err := credentials.Authorization(context.Background()); err == nil {
+		t.Fatal("expected error for expired session without refresh token")
+	}
+}
@@ -0,0 +1,370 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/devopzilla/guku-client-go"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// GukuClient is the set of guku API operations used by the resources and
+// data sources. Client implements it against the guku API, tests can provide
+// their own implementation through NewWithClient.
+type GukuClient interface {
+	GetCluster(ctx context.Context, id string) (*guku.Cluster, error)
+	ListClusters(ctx context.Context) ([]*guku.Cluster, error)
+	CreateCluster(ctx context.Context, name string, server string, ca string, token string, apiVersion string, clusterContext *string) (*guku.ClusterCreate, error)
+	UpdateCluster(ctx context.Context, id string, name *string, server *string, ca *string, token *string, apiVersion *string, clusterContext *string) (*guku.ClusterUpdate, error)
+	DeleteCluster(ctx context.Context, id string) (*guku.ClusterDelete, error)
+
+	GetPlatform(ctx context.Context, platformID string, platformVersion string) (*guku.Platform, error)
+
+	GetPlatformBinding(ctx context.Context, clusterID string, platformBindingID string) (*guku.PlatformBindingGet, error)
+	CreatePlatformBinding(ctx context.Context, clusterID string, platformID string, platformVersion string, platformConfigID string) (*guku.PlatformBindingCreate, error)
+	UpdatePlatformBinding(ctx context.Context, clusterID string, platformBindingID string, platformConfigID *string, platformVersion *string) (*guku.PlatformBindingUpdate, error)
+	DeletePlatformBinding(ctx context.Context, clusterID string, platformBindingID string) (*guku.PlatformBindingDelete, error)
+}
+
+var _ GukuClient = &Client{}
+
+// Client talks to the guku GraphQL API. It issues the same operations as
+// guku.Client but leaves authentication to the provider, so that both
+// Cognito logins and API tokens can be used.
+type Client struct {
+	graphqlClient graphql.Client
+	retryPolicy   RetryPolicy
+	limiter       *RequestLimiter
+}
+
+// ClientOptions configures how Client sends requests.
+type ClientOptions struct {
+	// HTTPClient sends the requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	RetryPolicy RetryPolicy
+
+	// MaxRequestsPerSecond and MaxConcurrentRequests limit the requests sent
+	// by the client, zero means unlimited.
+	MaxRequestsPerSecond  float64
+	MaxConcurrentRequests int
+}
+
+// Transport adds the authorization header to every GraphQL request.
+type Transport struct {
+	underlyingTransport http.RoundTripper
+	credentials         Credentials
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorization, err := t.credentials.Authorization(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrip must not modify the original request
+	req = req.Clone(req.Context())
+	req.Header.Set("authorization", authorization)
+
+	resp, err := t.underlyingTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// surface the status code so that failed requests can be classified
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = withErrorTypeExtensions(body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// errorTypeExtension is the extension GraphQL errors keep their AppSync
+// errorType in, see withErrorTypeExtensions.
+const errorTypeExtension = "errorType"
+
+// withErrorTypeExtensions copies the errorType AppSync reports next to the
+// message of GraphQL errors into their extensions, as only those are kept by
+// gqlerror. The body is returned unchanged when it has no such errors.
+func withErrorTypeExtensions(body []byte) []byte {
+	var resp struct {
+		Data       json.RawMessage          `json:"data,omitempty"`
+		Extensions json.RawMessage          `json:"extensions,omitempty"`
+		Errors     []map[string]interface{} `json:"errors,omitempty"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return body
+	}
+
+	rewritten := false
+	for _, gqlErr := range resp.Errors {
+		errorType, ok := gqlErr["errorType"].(string)
+		if !ok {
+			continue
+		}
+		extensions, _ := gqlErr["extensions"].(map[string]interface{})
+		if extensions == nil {
+			extensions = map[string]interface{}{}
+		}
+		extensions[errorTypeExtension] = errorType
+		gqlErr["extensions"] = extensions
+		rewritten = true
+	}
+	if !rewritten {
+		return body
+	}
+
+	encoded, err := json.Marshal(resp)
+	if err != nil {
+		return body
+	}
+	return encoded
+}
+
+func NewClient(url string, credentials Credentials, options ClientOptions) *Client {
+	baseClient := options.HTTPClient
+	if baseClient == nil {
+		baseClient = http.DefaultClient
+	}
+	underlyingTransport := baseClient.Transport
+	if underlyingTransport == nil {
+		underlyingTransport = http.DefaultTransport
+	}
+
+	httpClient := &http.Client{
+		Transport: &Transport{
+			underlyingTransport: underlyingTransport,
+			credentials:         credentials,
+		},
+		Timeout: baseClient.Timeout,
+	}
+
+	return &Client{
+		graphqlClient: graphql.NewClient(url, httpClient),
+		retryPolicy:   options.RetryPolicy,
+		limiter:       NewRequestLimiter(options.MaxRequestsPerSecond, options.MaxConcurrentRequests),
+	}
+}
+
+func (c *Client) makeRequest(ctx context.Context, opName string, query string, variables map[string]interface{}, data interface{}) error {
+	req := &graphql.Request{
+		OpName:    opName,
+		Query:     query,
+		Variables: variables,
+	}
+	mutation := strings.HasPrefix(strings.TrimSpace(query), "mutation")
+
+	for attempt := 1; ; attempt++ {
+		release, err := c.limiter.Acquire(ctx, opName)
+		if err != nil {
+			return err
+		}
+
+		err = c.graphqlClient.MakeRequest(ctx, req, &graphql.Response{Data: data})
+		release()
+
+		if err == nil || ctx.Err() != nil || attempt >= c.retryPolicy.MaxAttempts || !IsRetryable(err, mutation) {
+			return err
+		}
+
+		delay := c.retryPolicy.Delay(attempt)
+		tflog.Warn(ctx, fmt.Sprintf("Retrying %s in %s after attempt %d failed: %s", opName, delay, attempt, err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) GetCluster(ctx context.Context, id string) (*guku.Cluster, error) {
+	var data struct {
+		GetCluster *guku.Cluster `json:"getCluster"`
+	}
+	err := c.makeRequest(ctx, "getCluster", getClusterOperation, map[string]interface{}{
+		"id": id,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.GetCluster, nil
+}
+
+// listClusterPageSize is how many clusters are requested per listCluster
+// page. The guku API may return fewer, even with more pages to follow.
+const listClusterPageSize = 100
+
+// ListClusters returns all clusters, following the pages of listCluster until
+// no nextToken is returned.
+func (c *Client) ListClusters(ctx context.Context) ([]*guku.Cluster, error) {
+	var clusters []*guku.Cluster
+	var nextToken *string
+	for {
+		var data struct {
+			ListCluster *struct {
+				Items     []*guku.Cluster `json:"items"`
+				NextToken *string         `json:"nextToken"`
+			} `json:"listCluster"`
+		}
+		err := c.makeRequest(ctx, "listCluster", listClusterOperation, map[string]interface{}{
+			"limit":     listClusterPageSize,
+			"nextToken": nextToken,
+		}, &data)
+		if err != nil {
+			return nil, err
+		}
+		if data.ListCluster == nil {
+			return clusters, nil
+		}
+
+		clusters = append(clusters, data.ListCluster.Items...)
+		nextToken = data.ListCluster.NextToken
+		if nextToken == nil || *nextToken == "" {
+			return clusters, nil
+		}
+	}
+}
+
+func (c *Client) CreateCluster(ctx context.Context, name string, server string, ca string, token string, apiVersion string, clusterContext *string) (*guku.ClusterCreate, error) {
+	var data struct {
+		CreateCluster *guku.ClusterCreate `json:"createCluster"`
+	}
+	err := c.makeRequest(ctx, "createCluster", createClusterOperation, map[string]interface{}{
+		"name":       name,
+		"server":     server,
+		"ca":         ca,
+		"token":      token,
+		"apiVersion": apiVersion,
+		"context":    clusterContext,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.CreateCluster, nil
+}
+
+func (c *Client) UpdateCluster(ctx context.Context, id string, name *string, server *string, ca *string, token *string, apiVersion *string, clusterContext *string) (*guku.ClusterUpdate, error) {
+	var data struct {
+		UpdateCluster *guku.ClusterUpdate `json:"updateCluster"`
+	}
+	err := c.makeRequest(ctx, "updateCluster", updateClusterOperation, map[string]interface{}{
+		"id":         id,
+		"name":       name,
+		"server":     server,
+		"ca":         ca,
+		"token":      token,
+		"apiVersion": apiVersion,
+		"context":    clusterContext,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.UpdateCluster, nil
+}
+
+func (c *Client) DeleteCluster(ctx context.Context, id string) (*guku.ClusterDelete, error) {
+	var data struct {
+		DeleteCluster *guku.ClusterDelete `json:"deleteCluster"`
+	}
+	err := c.makeRequest(ctx, "deleteCluster", deleteClusterOperation, map[string]interface{}{
+		"id": id,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.DeleteCluster, nil
+}
+
+func (c *Client) GetPlatform(ctx context.Context, platformID string, platformVersion string) (*guku.Platform, error) {
+	var data struct {
+		GetPlatform *guku.Platform `json:"getPlatform"`
+	}
+	err := c.makeRequest(ctx, "getPlatform", getPlatformOperation, map[string]interface{}{
+		"platformID":      platformID,
+		"platformVersion": platformVersion,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.GetPlatform, nil
+}
+
+func (c *Client) GetPlatformBinding(ctx context.Context, clusterID string, platformBindingID string) (*guku.PlatformBindingGet, error) {
+	var data struct {
+		GetPlatformBinding *guku.PlatformBindingGet `json:"getPlatformBinding"`
+	}
+	err := c.makeRequest(ctx, "getPlatformBinding", getPlatformBindingOperation, map[string]interface{}{
+		"clusterID":         clusterID,
+		"platformBindingID": platformBindingID,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.GetPlatformBinding, nil
+}
+
+func (c *Client) CreatePlatformBinding(ctx context.Context, clusterID string, platformID string, platformVersion string, platformConfigID string) (*guku.PlatformBindingCreate, error) {
+	var data struct {
+		CreatePlatformBinding *guku.PlatformBindingCreate `json:"createPlatformBinding"`
+	}
+	err := c.makeRequest(ctx, "createPlatformBinding", createPlatformBindingOperation, map[string]interface{}{
+		"platformVersion":  platformVersion,
+		"platformID":       platformID,
+		"platformConfigID": platformConfigID,
+		"clusterID":        clusterID,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.CreatePlatformBinding, nil
+}
+
+func (c *Client) UpdatePlatformBinding(ctx context.Context, clusterID string, platformBindingID string, platformConfigID *string, platformVersion *string) (*guku.PlatformBindingUpdate, error) {
+	var data struct {
+		UpdatePlatformBinding *guku.PlatformBindingUpdate `json:"updatePlatformBinding"`
+	}
+	err := c.makeRequest(ctx, "updatePlatformBinding", updatePlatformBindingOperation, map[string]interface{}{
+		"clusterID":         clusterID,
+		"platformBindingID": platformBindingID,
+		"platformConfigID":  platformConfigID,
+		"platformVersion":   platformVersion,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.UpdatePlatformBinding, nil
+}
+
+func (c *Client) DeletePlatformBinding(ctx context.Context, clusterID string, platformBindingID string) (*guku.PlatformBindingDelete, error) {
+	var data struct {
+		DeletePlatformBinding *guku.PlatformBindingDelete `json:"deletePlatformBinding"`
+	}
+	err := c.makeRequest(ctx, "deletePlatformBinding", deletePlatformBindingOperation, map[string]interface{}{
+		"clusterID":         clusterID,
+		"platformBindingID": platformBindingID,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.DeletePlatformBinding, nil
+}
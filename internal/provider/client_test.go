@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		cancel()
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, &APITokenCredentials{Token: "test"}, ClientOptions{
+		RetryPolicy: RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour},
+	})
+
+	_, err := client.GetCluster(ctx, "c1")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected a single request, got %d", requests)
+	}
+
+	diagnostic := ClientErrorDiagnostic(ctx, "Unable to read cluster", err)
+	if diagnostic.Summary() != "Operation Cancelled" {
+		t.Errorf("expected cancelled diagnostic, got %q", diagnostic.Summary())
+	}
+}
+
+func TestClientErrorType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"getCluster":null},"errors":[{"path":["getCluster"],"errorType":"NotFoundException","message":"Cluster c1 not found"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, &APITokenCredentials{Token: "test"}, ClientOptions{})
+
+	// AppSync reports the error type next to the message
+	_, err := client.GetCluster(context.Background(), "c1")
+	if !IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is used by resources to wait for the guku API, so that tests can
+// replace the real clock and run without waiting.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep waits for d, returning early with the context error once ctx is
+	// done.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock waits in real time.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, d)
+}
+
+// instantClock returns without waiting, for backends where objects are ready
+// as soon as they are created. Its time does not advance by the time slept,
+// so waits on it count the time they slept themselves.
+type instantClock struct{}
+
+func (instantClock) Now() time.Time {
+	return time.Now()
+}
+
+func (instantClock) Sleep(ctx context.Context, d time.Duration) error {
+	return ctx.Err()
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// fakeClock records sleeps and returns from them immediately. Its time only
+// advances by the time slept.
+type fakeClock struct {
+	mu    sync.Mutex
+	slept []time.Duration
+	// onSleep, when set, is called on every sleep with the number of sleeps
+	// so far.
+	onSleep func(n int)
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Time{}
+	for _, slept := range c.slept {
+		now = now.Add(slept)
+	}
+	return now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	c.slept = append(c.slept, d)
+	n := len(c.slept)
+	c.mu.Unlock()
+
+	if c.onSleep != nil {
+		c.onSleep(n)
+	}
+	return ctx.Err()
+}
+
+// Slept returns the durations of all sleeps so far.
+func (c *fakeClock) Slept() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]time.Duration(nil), c.slept...)
+}
+
+// Count returns how many sleeps lasted d.
+func (c *fakeClock) Count(d time.Duration) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	for _, slept := range c.slept {
+		if slept == d {
+			count++
+		}
+	}
+	return count
+}
@@ -24,7 +24,8 @@ func NewClusterResource() resource.Resource {
 
 // ClusterResource defines the resource implementation.
 type ClusterResource struct {
-	client *guku.Client
+	client GukuClient
+	waiter *Waiter
 }
 
 // ClusterResourceModel describes the resource data model.
@@ -37,6 +38,8 @@ type ClusterResourceModel struct {
 	Ca         types.String `tfsdk:"ca"`
 	Server     types.String `tfsdk:"server"`
 	Context    types.String `tfsdk:"context"`
+
+	Timeouts *TimeoutsModel `tfsdk:"timeouts"`
 }
 
 func (r *ClusterResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -84,11 +87,21 @@ func (r *ClusterResource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Dia
 				Type:                types.StringType,
 			},
 			"context": {
-				MarkdownDescription: "Additional JSON cluster context",
+				MarkdownDescription: "Additional JSON cluster context. Changes to its formatting or key order are ignored.",
 				Optional:            true,
 				Type:                types.StringType,
+				PlanModifiers: tfsdk.AttributePlanModifiers{
+					jsonSemanticEqualityModifier{},
+				},
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(
+				fmt.Sprintf("`%s`", clusterCreateTimeout),
+				"no timeout",
+				fmt.Sprintf("`%s`", clusterDeleteTimeout),
+			),
+		},
 	}, nil
 }
 
@@ -98,18 +111,19 @@ func (r *ClusterResource) Configure(ctx context.Context, req resource.ConfigureR
 		return
 	}
 
-	client, ok := req.ProviderData.(*guku.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *guku.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.waiter = data.Waiter
 }
 
 func (r *ClusterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -122,25 +136,46 @@ func (r *ClusterResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	// data.Context.Value = MinifyJSONString(data.Context.Value)
+	createTimeout, diags := data.Timeouts.CreateTimeout(clusterCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	cluster, err := r.client.CreateCluster(
+		ctx,
 		data.Name.Value,
 		data.Server.Value,
 		data.Ca.Value,
 		data.Token.Value,
 		data.ApiVersion.Value,
-		data.Context.Value,
+		ValueStringOrNull(data.Context),
 	)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create cluster, got error: %s", err))
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to create cluster", err))
 		return
 	}
 
-	time.Sleep(time.Second * 40)
-
 	data.ClusterID = types.String{Value: cluster.GetClusterID()}
 
+	ready, err := r.waitForCluster(ctx, data, createTimeout)
+	if err != nil || !ready {
+		if err != nil {
+			resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to wait for cluster", err))
+		} else {
+			resp.Diagnostics.AddError(
+				"Cluster Not Ready",
+				fmt.Sprintf("Cluster %s was created, but the guku API did not report it as registered within %s. "+
+					"It was saved as tainted and will be replaced on the next apply. Increase timeouts.create if the guku API is slow to register clusters.",
+					data.ClusterID.Value, createTimeout),
+			)
+		}
+		// the cluster exists, save it so that it is tainted rather than lost
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
 	// Write logs using the tflog package
 	// Documentation: https://terraform.io/plugin/log
 	tflog.Trace(ctx, "created a cluster")
@@ -160,21 +195,35 @@ func (r *ClusterResource) Read(ctx context.Context, req resource.ReadRequest, re
 	}
 
 	cluster, err := r.client.GetCluster(
+		ctx,
 		data.ClusterID.Value,
 	)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read cluster, got error: %s", err))
+	if err != nil && !IsNotFound(err) {
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to read cluster", err))
+		return
+	}
+	if cluster == nil {
+		// deleted outside of terraform, plan to create it again
+		tflog.Warn(ctx, fmt.Sprintf("Cluster %s was not found, removing it from state", data.ClusterID.Value))
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
 	data.Name = types.String{Value: cluster.GetName()}
 	data.ApiVersion = types.String{Value: cluster.GetApiVersion()}
 
-	data.Ca = StringValueOrNull(cluster.GetCa())
-	data.Server = StringValueOrNull(cluster.GetServer())
-	data.Context = StringValueOrNull(cluster.GetContext())
-
-	data.Context.Value = MinifyJSONString(data.Context.Value)
+	data.Ca = StringValueOrNullIfEmpty(cluster.GetCa())
+	data.Server = StringValueOrNullIfEmpty(cluster.GetServer())
+	// keep the formatting of the prior state, which is the configured one,
+	// unless the context was changed outside of terraform
+	clusterContext := StringValueOrNull(cluster.GetContext())
+	if data.Context.IsNull() || clusterContext.IsNull() || !JSONSemanticallyEqual(data.Context.Value, clusterContext.Value) {
+		data.Context = clusterContext
+
+		if !data.Context.IsNull() {
+			data.Context.Value = MinifyJSONString(data.Context.Value)
+		}
+	}
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -190,22 +239,38 @@ func (r *ClusterResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
-	// if !data.Context.IsNull() {
-	// 	data.Context.Value = MinifyJSONString(data.Context.Value)
-	// }
+	// updates are applied by the time the guku API responds, so only the
+	// request is bounded, and only by an update timeout set in the
+	// configuration
+	updateCtx := ctx
+	if data.Timeouts.UpdateSet() {
+		updateTimeout, diags := data.Timeouts.UpdateTimeout(0)
+		resp.Diagnostics.Append(diags...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var cancel context.CancelFunc
+		updateCtx, cancel = context.WithTimeout(ctx, updateTimeout)
+		defer cancel()
+	}
 
 	_, err := r.client.UpdateCluster(
+		updateCtx,
 		data.ClusterID.Value,
 		ValueStringOrNull(data.Name),
-		ValueStringOrNull(data.Server),
-		ValueStringOrNull(data.Ca),
+		// the guku API requires server and ca, which are cleared by
+		// sending empty strings when they are removed from the configuration
+		&data.Server.Value,
+		&data.Ca.Value,
 		ValueStringOrNull(data.Token),
 		ValueStringOrNull(data.ApiVersion),
 		ValueStringOrNull(data.Context),
 	)
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update cluster, got error: %s", err))
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to update cluster", err))
 		return
 	}
 
@@ -225,20 +290,108 @@ func (r *ClusterResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
+	deleteTimeout, diags := data.Timeouts.DeleteTimeout(clusterDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	_, err := r.client.DeleteCluster(
+		ctx,
 		data.ClusterID.Value,
 	)
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete cluster, got error: %s", err))
+		// the cluster may have been deleted since it was last read
+		cluster, getErr := r.client.GetCluster(ctx, data.ClusterID.Value)
+		if (getErr != nil && !IsNotFound(getErr)) || cluster != nil {
+			resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to delete cluster", err))
+			return
+		}
+
+		tflog.Warn(ctx, fmt.Sprintf("Cluster %s was already deleted", data.ClusterID.Value))
 		return
 	}
 
-	time.Sleep(time.Second * 30)
+	deleted, err := r.waitForClusterDeletion(ctx, data.ClusterID.Value, deleteTimeout)
+	if err != nil {
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to wait for cluster deletion", err))
+		return
+	}
+	if !deleted && data.Timeouts.DeleteSet() {
+		resp.Diagnostics.AddError(
+			"Cluster Not Deleted",
+			fmt.Sprintf("Cluster %s was deleted, but the guku API still reported it after %s. "+
+				"Increase timeouts.delete if the guku API is slow to delete clusters.", data.ClusterID.Value, deleteTimeout),
+		)
+		return
+	}
+	if !deleted {
+		// the guku API accepted the deletion, which it finishes on its own
+		resp.Diagnostics.AddWarning(
+			"Cluster Not Deleted Yet",
+			fmt.Sprintf("Cluster %s was deleted, but the guku API still reported it after %s. "+
+				"It was removed from the state, and set timeouts.delete to wait longer for the guku API to delete clusters.", data.ClusterID.Value, deleteTimeout),
+		)
+	}
 
 	tflog.Trace(ctx, "deleted a cluster")
 }
 
+// waitForCluster polls the cluster until the guku API reports it as
+// registered. The API does not report whether a cluster is connected, so a
+// cluster is registered once it is read back with the configuration it was
+// created with, see clusterRegistered.
+func (r *ClusterResource) waitForCluster(ctx context.Context, data *ClusterResourceModel, timeout time.Duration) (bool, error) {
+	return r.waiter.PollUntil(ctx, timeout, func(ctx context.Context, poll int) (bool, error) {
+		tflog.Trace(ctx, fmt.Sprintf("Polling cluster %s attempt number %d", data.ClusterID.Value, poll))
+
+		cluster, err := r.client.GetCluster(ctx, data.ClusterID.Value)
+		if IsNotFound(err) {
+			// not registered yet
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return clusterRegistered(cluster, data), nil
+	})
+}
+
+// clusterRegistered reports whether the guku API holds the configuration of
+// data for the cluster, as sent by Create. The token is redacted by the API
+// and cannot be compared.
+func clusterRegistered(cluster *guku.Cluster, data *ClusterResourceModel) bool {
+	if cluster == nil || cluster.GetName() != data.Name.Value || cluster.GetApiVersion() != data.ApiVersion.Value {
+		return false
+	}
+	if stringValue(cluster.GetServer()) != data.Server.Value || stringValue(cluster.GetCa()) != data.Ca.Value {
+		return false
+	}
+	if cluster.GetContext() == nil || data.Context.IsNull() {
+		return cluster.GetContext() == nil && data.Context.IsNull()
+	}
+	return JSONSemanticallyEqual(*cluster.GetContext(), data.Context.Value)
+}
+
+// waitForClusterDeletion polls the cluster until the guku API no longer
+// reports it.
+func (r *ClusterResource) waitForClusterDeletion(ctx context.Context, clusterID string, timeout time.Duration) (bool, error) {
+	return r.waiter.PollUntil(ctx, timeout, func(ctx context.Context, poll int) (bool, error) {
+		tflog.Trace(ctx, fmt.Sprintf("Polling deleted cluster %s attempt number %d", clusterID, poll))
+
+		cluster, err := r.client.GetCluster(ctx, clusterID)
+		if IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return cluster == nil, nil
+	})
+}
+
 func (r *ClusterResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
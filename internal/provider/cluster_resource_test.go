@@ -0,0 +1,621 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/devopzilla/guku-client-go"
+	frameworkresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccClusterResource(t *testing.T) {
+	fake := newFakeGuku(t)
+	var clusterID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(&fakeClock{}),
+		CheckDestroy:             testAccCheckClusterDestroyed(fake),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: fake.ProviderConfig() + testAccClusterResourceConfig("https://one.example.com", "Y2Ex", `{ namespace = "one" }`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("guku_cluster.test", "name", "test"),
+					resource.TestCheckResourceAttr("guku_cluster.test", "api_version", "1.24"),
+					resource.TestCheckResourceAttr("guku_cluster.test", "server", "https://one.example.com"),
+					resource.TestCheckResourceAttr("guku_cluster.test", "ca", "Y2Ex"),
+					resource.TestCheckResourceAttr("guku_cluster.test", "context", `{"namespace":"one"}`),
+					testAccCheckClusterExists(fake, "guku_cluster.test", &clusterID),
+				),
+			},
+			// Update in place testing
+			{
+				Config: fake.ProviderConfig() + testAccClusterResourceConfig("https://two.example.com", "Y2Ey", `{ namespace = "two", labels = { env = "test" } }`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("guku_cluster.test", "server", "https://two.example.com"),
+					resource.TestCheckResourceAttr("guku_cluster.test", "ca", "Y2Ey"),
+					resource.TestCheckResourceAttr("guku_cluster.test", "context", `{"labels":{"env":"test"},"namespace":"two"}`),
+					testAccCheckClusterID(&clusterID, true),
+					func(s *terraform.State) error {
+						cluster := fake.Cluster(clusterID)
+						if cluster.GetServer() == nil || *cluster.GetServer() != "https://two.example.com" {
+							return fmt.Errorf("expected server to be updated, got %v", cluster.GetServer())
+						}
+						return nil
+					},
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "guku_cluster.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// the token is write only
+				ImportStateVerifyIgnore: []string{"token"},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccClusterResource_requiredOnly(t *testing.T) {
+	fake := newFakeGuku(t)
+	var clusterID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(&fakeClock{}),
+		CheckDestroy:             testAccCheckClusterDestroyed(fake),
+		Steps: []resource.TestStep{
+			// Optional attributes are read back as null
+			{
+				Config: fake.ProviderConfig() + `
+resource "guku_cluster" "test" {
+  name        = "test"
+  token       = "service-account-token"
+  api_version = "1.24"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("guku_cluster.test", "server"),
+					resource.TestCheckNoResourceAttr("guku_cluster.test", "ca"),
+					resource.TestCheckNoResourceAttr("guku_cluster.test", "context"),
+					testAccCheckClusterExists(fake, "guku_cluster.test", &clusterID),
+				),
+			},
+			{
+				ResourceName:            "guku_cluster.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"token"},
+			},
+		},
+	})
+}
+
+func TestAccClusterResource_deletedOutOfBand(t *testing.T) {
+	fake := newFakeGuku(t)
+	var clusterID string
+
+	config := fake.ProviderConfig() + testAccClusterResourceConfig("https://one.example.com", "Y2Ex", `{ namespace = "one" }`)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(&fakeClock{}),
+		CheckDestroy:             testAccCheckClusterDestroyed(fake),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testAccCheckClusterExists(fake, "guku_cluster.test", &clusterID),
+			},
+			// the cluster is created again
+			{
+				PreConfig: func() { fake.DeleteCluster(clusterID) },
+				Config:    config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckClusterID(&clusterID, false),
+					testAccCheckClusterExists(fake, "guku_cluster.test", &clusterID),
+				),
+			},
+			// as it is when the guku API reports it as not found
+			{
+				PreConfig: func() {
+					fake.DeleteCluster(clusterID)
+					fake.Fail("getCluster", notFoundErrorType, "Cluster not found")
+				},
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckClusterID(&clusterID, false),
+					testAccCheckClusterExists(fake, "guku_cluster.test", &clusterID),
+				),
+			},
+		},
+	})
+}
+
+func TestAccClusterResource_contextFormatting(t *testing.T) {
+	fake := newFakeGuku(t)
+
+	config := func(context string) string {
+		return fake.ProviderConfig() + fmt.Sprintf(`
+resource "guku_cluster" "test" {
+  name        = "test"
+  token       = "service-account-token"
+  api_version = "1.24"
+  context     = %s
+}
+`, context)
+	}
+	prettyContext := func(namespace string) string {
+		return fmt.Sprintf(`{
+  "replicas": 3,
+  "namespace": %q,
+  "labels": { "env": "test" }
+}
+`, namespace)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(&fakeClock{}),
+		CheckDestroy:             testAccCheckClusterDestroyed(fake),
+		Steps: []resource.TestStep{
+			// jsonencode output round-trips without drift
+			{
+				Config: config(`jsonencode({ replicas = 3, namespace = "one", labels = { env = "test" } })`),
+				Check:  resource.TestCheckResourceAttr("guku_cluster.test", "context", `{"labels":{"env":"test"},"namespace":"one","replicas":3}`),
+			},
+			// reformatting the same value shows no diff
+			{
+				Config:   config("<<EOT\n" + prettyContext("one") + "EOT"),
+				PlanOnly: true,
+			},
+			// the configured formatting is kept in state
+			{
+				Config: config("<<EOT\n" + prettyContext("two") + "EOT"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("guku_cluster.test", "context", prettyContext("two")),
+					func(s *terraform.State) error {
+						id := s.RootModule().Resources["guku_cluster.test"].Primary.ID
+						if context := fake.Cluster(id).GetContext(); context == nil || !JSONSemanticallyEqual(*context, prettyContext("two")) {
+							return fmt.Errorf("expected context to be updated, got %v", context)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccClusterResource_ready(t *testing.T) {
+	testCases := map[string]struct {
+		registrationReads int
+		timeout           string
+		expectError       *regexp.Regexp
+		waits             []time.Duration
+	}{
+		"immediately": {
+			timeout: "40s",
+		},
+		"eventually": {
+			registrationReads: 2,
+			timeout:           "2m",
+			waits:             []time.Duration{DefaultPollPolicy.Interval, DefaultPollPolicy.Interval},
+		},
+		"timeout": {
+			registrationReads: 3,
+			timeout:           "40s",
+			expectError:       regexp.MustCompile("did not report it as registered within 40s"),
+			waits:             []time.Duration{DefaultPollPolicy.Interval, 10 * time.Second},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeGuku(t)
+			fake.SetClusterRegistrationReads(testCase.registrationReads)
+			clock := &fakeClock{}
+
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(clock),
+				CheckDestroy:             testAccCheckClusterDestroyed(fake),
+				Steps: []resource.TestStep{
+					{
+						Config: fake.ProviderConfig() + fmt.Sprintf(`
+resource "guku_cluster" "test" {
+  name        = "test"
+  token       = "service-account-token"
+  api_version = "1.24"
+
+  timeouts {
+    create = %q
+  }
+}
+`, testCase.timeout),
+						ExpectError: testCase.expectError,
+						Check: resource.ComposeAggregateTestCheckFunc(
+							resource.TestCheckResourceAttr("guku_cluster.test", "timeouts.create", testCase.timeout),
+							func(s *terraform.State) error {
+								if slept := clock.Slept(); !reflect.DeepEqual(slept, testCase.waits) {
+									return fmt.Errorf("expected waits %v, got %v", testCase.waits, slept)
+								}
+								return nil
+							},
+						),
+					},
+				},
+			})
+
+			// the tainted cluster is destroyed afterwards, so only the
+			// first sleeps are waiting for it to be registered
+			if slept := clock.Slept(); testCase.expectError != nil && (len(slept) < len(testCase.waits) || !reflect.DeepEqual(slept[:len(testCase.waits)], testCase.waits)) {
+				t.Errorf("expected waits %v, got %v", testCase.waits, slept)
+			}
+		})
+	}
+}
+
+func TestAccClusterResource_delete(t *testing.T) {
+	testCases := map[string]struct {
+		deletionReads int
+		timeout       string
+		expectError   *regexp.Regexp
+		waits         []time.Duration
+	}{
+		"immediately": {
+			timeout: "30s",
+		},
+		"eventually": {
+			deletionReads: 2,
+			timeout:       "2m",
+			waits:         []time.Duration{DefaultPollPolicy.Interval, DefaultPollPolicy.Interval},
+		},
+		"timeout": {
+			deletionReads: 3,
+			timeout:       "40s",
+			expectError:   regexp.MustCompile("still reported it after 40s"),
+			waits:         []time.Duration{DefaultPollPolicy.Interval, 10 * time.Second},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeGuku(t)
+			clock := &fakeClock{}
+			var clusterID string
+			// sleeps before deletion, which are not checked
+			var created int
+
+			config := fake.ProviderConfig() + fmt.Sprintf(`
+resource "guku_cluster" "test" {
+  name        = "test"
+  token       = "service-account-token"
+  api_version = "1.24"
+
+  timeouts {
+    delete = %q
+  }
+}
+`, testCase.timeout)
+
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(clock),
+				CheckDestroy:             testAccCheckClusterDestroyed(fake),
+				Steps: []resource.TestStep{
+					{
+						Config: config,
+						Check:  testAccCheckClusterExists(fake, "guku_cluster.test", &clusterID),
+					},
+					{
+						PreConfig: func() {
+							fake.SetClusterDeletionReads(testCase.deletionReads)
+							created = len(clock.Slept())
+						},
+						Config:      config,
+						Destroy:     true,
+						ExpectError: testCase.expectError,
+					},
+				},
+			})
+
+			if slept := clock.Slept()[created:]; !reflect.DeepEqual(slept, testCase.waits) {
+				t.Errorf("expected waits %v, got %v", testCase.waits, slept)
+			}
+		})
+	}
+}
+
+func TestClusterResourceDelete_alreadyDeleted(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := NewMemoryClient(MemoryEndpointPrefix, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	r := &ClusterResource{client: client, waiter: &Waiter{Clock: &fakeClock{}, Policy: DefaultPollPolicy}}
+
+	schema, diags := r.GetSchema(ctx)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	state := tfsdk.State{Schema: schema, Raw: tftypes.NewValue(schema.Type().TerraformType(ctx), nil)}
+	diags = state.Set(ctx, &ClusterResourceModel{
+		ClusterID:  types.String{Value: "cluster-1"},
+		Name:       types.String{Value: "test"},
+		Token:      types.String{Value: "service-account-token"},
+		ApiVersion: types.String{Value: "1.24"},
+		Ca:         types.String{Null: true},
+		Server:     types.String{Null: true},
+		Context:    types.String{Null: true},
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	resp := &frameworkresource.DeleteResponse{State: state}
+	r.Delete(ctx, frameworkresource.DeleteRequest{State: state}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Errorf("expected an already deleted cluster to be deleted without errors, got %v", resp.Diagnostics)
+	}
+}
+
+func TestClusterResourceDelete_defaultTimeout(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGuku(t)
+	clusterID := fake.AddCluster(guku.Cluster{Name: "test", ApiVersion: "1.24"})
+	fake.SetClusterDeletionReads(3)
+
+	client := NewClient(fake.Endpoint(), &APITokenCredentials{Token: fakeGukuAPIToken}, ClientOptions{})
+	r := &ClusterResource{client: client, waiter: &Waiter{Clock: &fakeClock{}, Policy: DefaultPollPolicy}}
+
+	schema, diags := r.GetSchema(ctx)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	state := tfsdk.State{Schema: schema, Raw: tftypes.NewValue(schema.Type().TerraformType(ctx), nil)}
+	diags = state.Set(ctx, &ClusterResourceModel{
+		ClusterID:  types.String{Value: clusterID},
+		Name:       types.String{Value: "test"},
+		Token:      types.String{Value: "service-account-token"},
+		ApiVersion: types.String{Value: "1.24"},
+		Ca:         types.String{Null: true},
+		Server:     types.String{Null: true},
+		Context:    types.String{Null: true},
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	resp := &frameworkresource.DeleteResponse{State: state}
+	r.Delete(ctx, frameworkresource.DeleteRequest{State: state}, resp)
+
+	// a deletion outlasting the default timeout succeeds with a warning
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 {
+		t.Errorf("expected a warning, got %v", resp.Diagnostics)
+	}
+}
+
+func TestClusterResourceWaitForCluster(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGuku(t)
+	fake.SetClusterRegistrationReads(2)
+	fake.Fail("getCluster", notFoundErrorType, "Cluster not found")
+
+	client := NewClient(fake.Endpoint(), &APITokenCredentials{Token: fakeGukuAPIToken}, ClientOptions{})
+	clusterContext := `{"namespace": "default"}`
+	cluster, err := client.CreateCluster(ctx, "test", "https://kubernetes", "", "service-account-token", "1.24", &clusterContext)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	r := &ClusterResource{client: client, waiter: &Waiter{Clock: &fakeClock{}, Policy: DefaultPollPolicy}}
+	data := &ClusterResourceModel{
+		ClusterID:  types.String{Value: cluster.GetClusterID()},
+		Name:       types.String{Value: "test"},
+		ApiVersion: types.String{Value: "1.24"},
+		Server:     types.String{Value: "https://kubernetes"},
+		Ca:         types.String{Null: true},
+		Context:    types.String{Value: "{\n  \"namespace\": \"default\"\n}"},
+	}
+
+	ready, err := r.waitForCluster(ctx, data, 2*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !ready {
+		t.Error("expected cluster to be registered")
+	}
+	// neither the not found error nor the reads without the configuration
+	// are taken as registered
+	if n := fake.Requests("getCluster"); n != 4 {
+		t.Errorf("expected 4 reads, got %d", n)
+	}
+}
+
+func TestClusterResourceWaitForClusterDeletion_notFound(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGuku(t)
+	clusterID := fake.AddCluster(guku.Cluster{Name: "test", ApiVersion: "1.24"})
+	fake.Fail("getCluster", notFoundErrorType, "Cluster not found")
+
+	client := NewClient(fake.Endpoint(), &APITokenCredentials{Token: fakeGukuAPIToken}, ClientOptions{})
+	r := &ClusterResource{client: client, waiter: &Waiter{Clock: &fakeClock{}, Policy: DefaultPollPolicy}}
+
+	deleted, err := r.waitForClusterDeletion(ctx, clusterID, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !deleted {
+		t.Error("expected a cluster reported as not found to be deleted")
+	}
+}
+
+func TestClusterRegistered(t *testing.T) {
+	server := "https://kubernetes"
+	clusterContext := `{"namespace":"default"}`
+	registered := guku.Cluster{ClusterID: "cluster-1", Name: "test", ApiVersion: "1.24", Server: &server, Context: &clusterContext}
+	data := &ClusterResourceModel{
+		ClusterID:  types.String{Value: "cluster-1"},
+		Name:       types.String{Value: "test"},
+		ApiVersion: types.String{Value: "1.24"},
+		Server:     types.String{Value: server},
+		Ca:         types.String{Null: true},
+		Context:    types.String{Value: `{ "namespace": "default" }`},
+	}
+
+	otherServer := "https://other"
+	otherContext := `{"namespace":"other"}`
+	testCases := map[string]struct {
+		cluster  func(c *guku.Cluster) *guku.Cluster
+		expected bool
+	}{
+		"registered": {
+			cluster:  func(c *guku.Cluster) *guku.Cluster { return c },
+			expected: true,
+		},
+		"missing": {
+			cluster: func(c *guku.Cluster) *guku.Cluster { return nil },
+		},
+		"without configuration": {
+			cluster: func(c *guku.Cluster) *guku.Cluster {
+				return &guku.Cluster{ClusterID: c.ClusterID, Name: c.Name}
+			},
+		},
+		"other name": {
+			cluster: func(c *guku.Cluster) *guku.Cluster { c.Name = "other"; return c },
+		},
+		"other server": {
+			cluster: func(c *guku.Cluster) *guku.Cluster { c.Server = &otherServer; return c },
+		},
+		"other context": {
+			cluster: func(c *guku.Cluster) *guku.Cluster { c.Context = &otherContext; return c },
+		},
+		"without context": {
+			cluster: func(c *guku.Cluster) *guku.Cluster { c.Context = nil; return c },
+		},
+	}
+
+	for name, testCase := range testCases {
+		cluster := registered
+		if actual := clusterRegistered(testCase.cluster(&cluster), data); actual != testCase.expected {
+			t.Errorf("%s: expected %t, got %t", name, testCase.expected, actual)
+		}
+	}
+}
+
+func testAccClusterResourceConfig(server string, ca string, context string) string {
+	return fmt.Sprintf(`
+resource "guku_cluster" "test" {
+  name        = "test"
+  token       = "service-account-token"
+  api_version = "1.24"
+  server      = %q
+  ca          = %q
+  context     = jsonencode(%s)
+}
+`, server, ca, context)
+}
+
+func testAccRecordedClusterResourceConfig(server string) string {
+	return fmt.Sprintf(`
+resource "guku_cluster" "test" {
+  name        = "tf-acc-cluster"
+  token       = "service-account-token"
+  api_version = "1.24"
+  server      = %q
+  ca          = "Y2E="
+  context     = jsonencode({ namespace = "default" })
+}
+`, server)
+}
+
+// testAccCheckClusterExists checks that the cluster in state exists in the
+// fake and stores its ID.
+func testAccCheckClusterExists(fake *fakeGuku, name string, clusterID *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s not found in state", name)
+		}
+		if fake.Cluster(rs.Primary.ID) == nil {
+			return fmt.Errorf("cluster %s does not exist", rs.Primary.ID)
+		}
+		*clusterID = rs.Primary.ID
+		return nil
+	}
+}
+
+// testAccCheckClusterID checks whether guku_cluster.test still has the ID
+// stored by testAccCheckClusterExists.
+func testAccCheckClusterID(clusterID *string, same bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources["guku_cluster.test"].Primary.ID
+		if same && id != *clusterID {
+			return fmt.Errorf("expected cluster %s to be updated in place, got %s", *clusterID, id)
+		}
+		if !same && id == *clusterID {
+			return fmt.Errorf("expected cluster %s to be replaced", id)
+		}
+		return nil
+	}
+}
+
+func testAccCheckClusterDestroyed(fake *fakeGuku) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if ids := fake.ClusterIDs(); len(ids) > 0 {
+			return fmt.Errorf("expected all clusters to be destroyed, found %v", ids)
+		}
+		return nil
+	}
+}
+
+func TestAccClusterResource_recorded(t *testing.T) {
+	recorder := newRecorder(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: recorder.ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: recorder.ProviderConfig() + testAccRecordedClusterResourceConfig("https://kubernetes.tf-acc.example.com"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("guku_cluster.test", "name", "tf-acc-cluster"),
+					resource.TestCheckResourceAttr("guku_cluster.test", "server", "https://kubernetes.tf-acc.example.com"),
+					resource.TestCheckResourceAttr("guku_cluster.test", "context", `{"namespace":"default"}`),
+					resource.TestCheckResourceAttrSet("guku_cluster.test", "id"),
+				),
+			},
+			{
+				Config: recorder.ProviderConfig() + testAccRecordedClusterResourceConfig("https://kubernetes-2.tf-acc.example.com"),
+				Check:  resource.TestCheckResourceAttr("guku_cluster.test", "server", "https://kubernetes-2.tf-acc.example.com"),
+			},
+			{
+				ResourceName:            "guku_cluster.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"token"},
+			},
+		},
+	})
+}
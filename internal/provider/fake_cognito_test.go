@@ -0,0 +1,393 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCognitoConfig is the user pool served by fakeCognito.
+var fakeCognitoConfig = CognitoConfig{
+	Region:     "eu-west-1",
+	UserPoolID: "eu-west-1_fake",
+	ClientID:   "fake-client",
+}
+
+// fakeCognitoPoolName is the user pool ID without its region, which SRP
+// hashes into the password verifier.
+const fakeCognitoPoolName = "fake"
+
+// fakeCognito is an in-process stand-in for the Cognito user pool API. It
+// implements the SRP login of CognitoLogin and the refresh of
+// CognitoCredentials, and issues tokens that expire after expiresIn seconds.
+//
+// Cognito is always reached at its regional AWS endpoint, so requests are
+// routed to the fake by the transport of HTTPClient.
+type fakeCognito struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	expiresIn int32
+	issued    int
+	// users maps usernames to their password.
+	users map[string]string
+	// refreshTokens maps the refresh tokens the fake accepts to their user.
+	refreshTokens map[string]string
+	// idTokens are all id tokens issued.
+	idTokens map[string]bool
+	// challenges are the SRP logins waiting for the PASSWORD_VERIFIER
+	// challenge response, keyed by their secret block.
+	challenges map[string]*fakeSRPChallenge
+	requests   map[string]int
+}
+
+type fakeSRPChallenge struct {
+	username string
+	salt     *big.Int
+	verifier *big.Int
+	a        *big.Int
+	b        *big.Int
+	bigB     *big.Int
+}
+
+type fakeCognitoRequest struct {
+	AuthFlow       string            `json:"AuthFlow"`
+	ClientId       string            `json:"ClientId"`
+	AuthParameters map[string]string `json:"AuthParameters"`
+}
+
+type fakeCognitoChallengeRequest struct {
+	ChallengeName      string            `json:"ChallengeName"`
+	ClientId           string            `json:"ClientId"`
+	ChallengeResponses map[string]string `json:"ChallengeResponses"`
+}
+
+type fakeCognitoAuthenticationResult struct {
+	IdToken      string `json:"IdToken"`
+	AccessToken  string `json:"AccessToken"`
+	RefreshToken string `json:"RefreshToken,omitempty"`
+	ExpiresIn    int32  `json:"ExpiresIn"`
+	TokenType    string `json:"TokenType"`
+}
+
+// newFakeCognito starts a fakeCognito that is closed when the test finishes.
+func newFakeCognito(t *testing.T) *fakeCognito {
+	// the Cognito client loads the shared AWS configuration, keep the
+	// environment the tests run in out of it
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	f := &fakeCognito{
+		expiresIn:     3600,
+		users:         map[string]string{},
+		refreshTokens: map[string]string{},
+		idTokens:      map[string]bool{},
+		challenges:    map[string]*fakeSRPChallenge{},
+		requests:      map[string]int{},
+	}
+
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// HTTPClient returns a client that sends all requests to the fake.
+func (f *fakeCognito) HTTPClient() *http.Client {
+	return &http.Client{Transport: f.Wrap(http.DefaultTransport)}
+}
+
+// Wrap returns a transport that sends Cognito requests to the fake and all
+// other requests to next.
+func (f *fakeCognito) Wrap(next http.RoundTripper) http.RoundTripper {
+	target, _ := url.Parse(f.server.URL)
+	return fakeCognitoTransport{target: target, next: next}
+}
+
+type fakeCognitoTransport struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (t fakeCognitoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.Host, "cognito-idp.") {
+		return t.next.RoundTrip(req)
+	}
+
+	// RoundTrip must not modify the original request
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// SetExpiresIn sets the lifetime in seconds of the tokens issued from now on.
+func (f *fakeCognito) SetExpiresIn(expiresIn int32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.expiresIn = expiresIn
+}
+
+// AddUser adds a user that may log in with password.
+func (f *fakeCognito) AddUser(username string, password string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.users[username] = password
+}
+
+// AddRefreshToken makes the fake accept token as a refresh token of username.
+func (f *fakeCognito) AddRefreshToken(token string, username string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.refreshTokens[token] = username
+}
+
+// ValidIDToken reports whether token is an id token issued by the fake.
+func (f *fakeCognito) ValidIDToken(token string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.idTokens[token]
+}
+
+// Requests returns how many InitiateAuth requests of the auth flow were
+// served.
+func (f *fakeCognito) Requests(authFlow string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requests[authFlow]
+}
+
+func (f *fakeCognito) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch target := r.Header.Get("X-Amz-Target"); target {
+	case "AWSCognitoIdentityProviderService.InitiateAuth":
+		var req fakeCognitoRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.writeError(w, "InvalidParameterException", err.Error())
+			return
+		}
+		f.requests[req.AuthFlow]++
+		f.initiateAuth(w, req)
+	case "AWSCognitoIdentityProviderService.RespondToAuthChallenge":
+		var req fakeCognitoChallengeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			f.writeError(w, "InvalidParameterException", err.Error())
+			return
+		}
+		f.respondToAuthChallenge(w, req)
+	default:
+		f.writeError(w, "InvalidAction", fmt.Sprintf("unsupported action %q", target))
+	}
+}
+
+func (f *fakeCognito) initiateAuth(w http.ResponseWriter, req fakeCognitoRequest) {
+	switch req.AuthFlow {
+	case "REFRESH_TOKEN_AUTH":
+		username, ok := f.refreshTokens[req.AuthParameters["REFRESH_TOKEN"]]
+		if !ok {
+			f.writeError(w, "NotAuthorizedException", "Invalid Refresh Token")
+			return
+		}
+		// like Cognito without refresh token rotation, the refresh token is
+		// not reissued
+		f.writeResult(w, f.issue(username, ""))
+	case "USER_SRP_AUTH":
+		f.startSRPChallenge(w, req.AuthParameters["USERNAME"], req.AuthParameters["SRP_A"])
+	default:
+		f.writeError(w, "InvalidParameterException", fmt.Sprintf("unsupported auth flow %q", req.AuthFlow))
+	}
+}
+
+// startSRPChallenge answers the first step of an SRP login with the
+// PASSWORD_VERIFIER challenge, following the server side of the protocol as
+// implemented by Cognito.
+func (f *fakeCognito) startSRPChallenge(w http.ResponseWriter, username string, srpA string) {
+	password, ok := f.users[username]
+	if !ok {
+		f.writeError(w, "NotAuthorizedException", "Incorrect username or password.")
+		return
+	}
+	a, ok := new(big.Int).SetString(srpA, 16)
+	if !ok || new(big.Int).Mod(a, srpN).Sign() == 0 {
+		f.writeError(w, "InvalidParameterException", "invalid SRP_A")
+		return
+	}
+
+	challenge := &fakeSRPChallenge{
+		username: username,
+		salt:     fakeSRPRandom(16),
+		a:        a,
+		b:        fakeSRPRandom(128),
+	}
+	challenge.verifier = new(big.Int).Exp(srpG, srpX(challenge.salt, username, password), srpN)
+	// B = k*v + g^b
+	challenge.bigB = new(big.Int).Mul(srpK, challenge.verifier)
+	challenge.bigB.Add(challenge.bigB, new(big.Int).Exp(srpG, challenge.b, srpN))
+	challenge.bigB.Mod(challenge.bigB, srpN)
+
+	secretBlock := base64.StdEncoding.EncodeToString(fakeSRPRandom(64).Bytes())
+	f.challenges[secretBlock] = challenge
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ChallengeName": "PASSWORD_VERIFIER",
+		"ChallengeParameters": map[string]string{
+			"USERNAME":        username,
+			"USER_ID_FOR_SRP": username,
+			"SALT":            challenge.salt.Text(16),
+			"SRP_B":           challenge.bigB.Text(16),
+			"SECRET_BLOCK":    secretBlock,
+		},
+	})
+}
+
+// respondToAuthChallenge verifies the password claim of an SRP login.
+func (f *fakeCognito) respondToAuthChallenge(w http.ResponseWriter, req fakeCognitoChallengeRequest) {
+	if req.ChallengeName != "PASSWORD_VERIFIER" {
+		f.writeError(w, "InvalidParameterException", fmt.Sprintf("unsupported challenge %q", req.ChallengeName))
+		return
+	}
+
+	secretBlock := req.ChallengeResponses["PASSWORD_CLAIM_SECRET_BLOCK"]
+	challenge, ok := f.challenges[secretBlock]
+	if !ok || challenge.username != req.ChallengeResponses["USERNAME"] {
+		f.writeError(w, "NotAuthorizedException", "Invalid session for the user.")
+		return
+	}
+	delete(f.challenges, secretBlock)
+
+	// S = (A * v^u)^b
+	u := srpHash(srpPadHex(challenge.a) + srpPadHex(challenge.bigB))
+	s := new(big.Int).Exp(challenge.verifier, u, srpN)
+	s.Mul(s, challenge.a)
+	s.Exp(s, challenge.b, srpN)
+	key := srpHKDF(srpPadHex(s), srpPadHex(u))
+
+	secretBlockBytes, _ := base64.StdEncoding.DecodeString(secretBlock)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(fakeCognitoPoolName + challenge.username + string(secretBlockBytes) + req.ChallengeResponses["TIMESTAMP"]))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(req.ChallengeResponses["PASSWORD_CLAIM_SIGNATURE"])) {
+		f.writeError(w, "NotAuthorizedException", "Incorrect username or password.")
+		return
+	}
+
+	f.issued++
+	refreshToken := fmt.Sprintf("refresh-%s-%d", challenge.username, f.issued)
+	f.refreshTokens[refreshToken] = challenge.username
+	f.writeResult(w, f.issue(challenge.username, refreshToken))
+}
+
+// issue returns new tokens for username.
+func (f *fakeCognito) issue(username string, refreshToken string) fakeCognitoAuthenticationResult {
+	f.issued++
+	idToken := fmt.Sprintf("id-%s-%d", username, f.issued)
+	f.idTokens[idToken] = true
+	return fakeCognitoAuthenticationResult{
+		IdToken:      idToken,
+		AccessToken:  fmt.Sprintf("access-%s-%d", username, f.issued),
+		RefreshToken: refreshToken,
+		ExpiresIn:    f.expiresIn,
+		TokenType:    "Bearer",
+	}
+}
+
+func (f *fakeCognito) writeResult(w http.ResponseWriter, result fakeCognitoAuthenticationResult) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	json.NewEncoder(w).Encode(map[string]interface{}{"AuthenticationResult": result})
+}
+
+func (f *fakeCognito) writeError(w http.ResponseWriter, errorType string, message string) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"__type": errorType, "message": message})
+}
+
+// The SRP group and multiplier used by Cognito, see
+// github.com/alexrudd/cognito-srp.
+var (
+	srpN, _ = new(big.Int).SetString(""+
+		"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1"+
+		"29024E088A67CC74020BBEA63B139B22514A08798E3404DD"+
+		"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245"+
+		"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+		"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D"+
+		"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F"+
+		"83655D23DCA3AD961C62F356208552BB9ED529077096966D"+
+		"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B"+
+		"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9"+
+		"DE2BCBF6955817183995497CEA956AE515D2261898FA0510"+
+		"15728E5A8AAAC42DAD33170D04507A33A85521ABDF1CBA64"+
+		"ECFB850458DBEF0A8AEA71575D060C7DB3970F85A6E1E4C7"+
+		"ABF5AE8CDB0933D71E8C94E04A25619DCEE3D2261AD2EE6B"+
+		"F12FFA06D98A0864D87602733EC86A64521F2B18177B200C"+
+		"BBE117577A615D6C770988C0BAD946E208E24FA074E5AB31"+
+		"43DB5BFCE0FD108E4B82D120A93AD2CAFFFFFFFFFFFFFFFF", 16)
+	srpG = big.NewInt(2)
+	srpK = srpHash("00" + srpN.Text(16) + "0" + srpG.Text(16))
+)
+
+// srpX returns the private key derived from the password of username.
+func srpX(salt *big.Int, username string, password string) *big.Int {
+	userPass := sha256.Sum256([]byte(fakeCognitoPoolName + username + ":" + password))
+	return srpHash(srpPadHex(salt) + hex.EncodeToString(userPass[:]))
+}
+
+// srpHash returns the SHA-256 hash of the hex encoded bytes as a number.
+func srpHash(hexStr string) *big.Int {
+	buf, _ := hex.DecodeString(hexStr)
+	sum := sha256.Sum256(buf)
+	return new(big.Int).SetBytes(sum[:])
+}
+
+// srpPadHex hex encodes val the way Cognito hashes it, as an even number of
+// digits that does not read as a negative number.
+func srpPadHex(val *big.Int) string {
+	hexStr := val.Text(16)
+	if len(hexStr)%2 == 1 {
+		return "0" + hexStr
+	}
+	if strings.ContainsAny(hexStr[:1], "89abcdef") {
+		return "00" + hexStr
+	}
+	return hexStr
+}
+
+// srpHKDF derives the 16 byte password authentication key.
+func srpHKDF(ikm string, salt string) []byte {
+	ikmBytes, _ := hex.DecodeString(ikm)
+	saltBytes, _ := hex.DecodeString(salt)
+
+	extractor := hmac.New(sha256.New, saltBytes)
+	extractor.Write(ikmBytes)
+	expander := hmac.New(sha256.New, extractor.Sum(nil))
+	expander.Write(append([]byte("Caldera Derived Key"), 1))
+	return expander.Sum(nil)[:16]
+}
+
+func fakeSRPRandom(n int) *big.Int {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return new(big.Int).Mod(new(big.Int).SetBytes(buf), srpN)
+}
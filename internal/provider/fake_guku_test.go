@@ -0,0 +1,668 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/devopzilla/guku-client-go"
+)
+
+const (
+	fakeGukuAPIToken    = "fake-api-token"
+	fakeGukuOIDCToken   = "fake-oidc-token"
+	fakeGukuAccessToken = "fake-access-token"
+	fakeGukuUsername    = "fake-user"
+	fakeGukuPassword    = "fake-password"
+)
+
+// fakeGuku is an in-process stand-in for the guku GraphQL API. It serves the
+// operations issued by Client at /graphql and an OIDC token exchange endpoint
+// at /token. Clusters, platform bindings and platforms are kept in a
+// MemoryClient, and the fake scripts how the guku API deviates from it, such
+// as slow registrations, binding status transitions and failures.
+//
+// Requests must be authorized with fakeGukuAPIToken, with the access token
+// issued for fakeGukuOIDCToken by the exchange endpoint, or with an id token
+// issued by Cognito, which is a fakeCognito with the user fakeGukuUsername.
+type fakeGuku struct {
+	server  *httptest.Server
+	cognito *fakeCognito
+	store   *MemoryClient
+
+	mu sync.Mutex
+	// bindingStatuses are the statuses new and updated bindings go through,
+	// see SetBindingStatuses, and transitions are the statuses bindings
+	// report on their following reads, the last one sticks.
+	bindingStatuses []guku.PlatformBindingStatus
+	transitions     map[string][]guku.PlatformBindingStatus
+	// registrationReads is how many reads report new clusters without their
+	// configuration, see SetClusterRegistrationReads, and unregistered counts them down.
+	registrationReads int
+	unregistered      map[string]int
+	// deletionReads is how many reads still report deleted clusters, see
+	// SetClusterDeletionReads, and deleting holds them until then.
+	deletionReads int
+	deleting      map[string]*fakeDeletingCluster
+	// listPageSize caps the clusters returned per listCluster page, see
+	// SetListClusterPageSize.
+	listPageSize int
+	// failures are GraphQL errors returned by the next requests of an
+	// operation, see Fail.
+	failures map[string][]*fakeGraphQLError
+	requests map[string]int
+}
+
+type fakeDeletingCluster struct {
+	cluster *guku.Cluster
+	reads   int
+}
+
+type fakeGraphQLRequest struct {
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName"`
+	Variables     json.RawMessage `json:"variables"`
+}
+
+// newFakeGuku starts a fakeGuku that is closed when the test finishes.
+func newFakeGuku(t *testing.T) *fakeGuku {
+	store, err := NewMemoryClient(MemoryEndpointPrefix, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeGuku{
+		store:           store,
+		bindingStatuses: []guku.PlatformBindingStatus{guku.PlatformBindingStatusSucceeded},
+		transitions:     map[string][]guku.PlatformBindingStatus{},
+		unregistered:    map[string]int{},
+		deleting:        map[string]*fakeDeletingCluster{},
+		failures:        map[string][]*fakeGraphQLError{},
+		requests:        map[string]int{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", f.serveGraphQL)
+	mux.HandleFunc("/token", f.serveTokenExchange)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	f.cognito = newFakeCognito(t)
+	f.cognito.AddUser(fakeGukuUsername, fakeGukuPassword)
+
+	return f
+}
+
+// Endpoint returns the GraphQL endpoint to configure the provider with.
+func (f *fakeGuku) Endpoint() string {
+	return f.server.URL + "/graphql"
+}
+
+// TokenExchangeURL returns the OIDC token exchange endpoint.
+func (f *fakeGuku) TokenExchangeURL() string {
+	return f.server.URL + "/token"
+}
+
+// ProviderConfig returns a provider block that connects to the fake.
+func (f *fakeGuku) ProviderConfig() string {
+	return fmt.Sprintf(`
+provider "guku" {
+  endpoint  = %q
+  api_token = %q
+}
+`, f.Endpoint(), fakeGukuAPIToken)
+}
+
+// PasswordProviderConfig returns a provider block that logs in to the fake
+// with username and password. The provider must send its Cognito requests
+// through Cognito().Wrap.
+func (f *fakeGuku) PasswordProviderConfig() string {
+	return fmt.Sprintf(`
+provider "guku" {
+  endpoint              = %q
+  cognito_user_pool_id  = %q
+  cognito_client_id     = %q
+  region                = %q
+  username              = %q
+  password              = %q
+  disable_session_cache = true
+}
+`, f.Endpoint(), fakeCognitoConfig.UserPoolID, fakeCognitoConfig.ClientID, fakeCognitoConfig.Region, fakeGukuUsername, fakeGukuPassword)
+}
+
+// Cognito returns the user pool the fake accepts id tokens of.
+func (f *fakeGuku) Cognito() *fakeCognito {
+	return f.cognito
+}
+
+// AddCluster stores a cluster and returns its ID.
+func (f *fakeGuku) AddCluster(cluster guku.Cluster) string {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	if cluster.ClusterID == "" {
+		cluster.ClusterID = f.store.nextID("cluster")
+	}
+	f.store.state.Clusters[cluster.ClusterID] = &cluster
+	return cluster.ClusterID
+}
+
+// AddPlatform adds a platform to the catalog.
+func (f *fakeGuku) AddPlatform(platform guku.Platform) {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	f.store.platforms = append(f.store.platforms, platform)
+}
+
+// SetBindingStatuses scripts the statuses that bindings go through after they
+// are created or updated. The mutation returns the first status, and every
+// read moves on to the next one until the last is reached.
+func (f *fakeGuku) SetBindingStatuses(statuses ...guku.PlatformBindingStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.bindingStatuses = statuses
+}
+
+// SetClusterRegistrationReads makes the next reads of clusters created from
+// now on report them without their API version, server, CA and context, as
+// if the guku API had not registered them yet.
+func (f *fakeGuku) SetClusterRegistrationReads(reads int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.registrationReads = reads
+}
+
+// SetClusterDeletionReads makes the next reads of clusters deleted from now
+// on still report them, as if the guku API had not finished deleting them.
+func (f *fakeGuku) SetClusterDeletionReads(reads int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deletionReads = reads
+}
+
+// SetListClusterPageSize makes listCluster return at most size clusters per
+// page, fewer than requested, as the guku API does when a page reaches its
+// size limit.
+func (f *fakeGuku) SetListClusterPageSize(size int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.listPageSize = size
+}
+
+// Fail makes the next request of the operation fail with a GraphQL error of
+// the error type. Calling it repeatedly queues further failures.
+func (f *fakeGuku) Fail(operation string, errorType string, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures[operation] = append(f.failures[operation], &fakeGraphQLError{errorType: errorType, message: message})
+}
+
+// fakeGraphQLError is a GraphQL error with the errorType AppSync reports.
+type fakeGraphQLError struct {
+	errorType string
+	message   string
+}
+
+func (e *fakeGraphQLError) Error() string {
+	return e.message
+}
+
+// Requests returns how many requests of the operation were served.
+func (f *fakeGuku) Requests(operation string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requests[operation]
+}
+
+// Cluster returns a copy of the stored cluster, or nil.
+func (f *fakeGuku) Cluster(id string) *guku.Cluster {
+	cluster, _ := f.store.GetCluster(context.Background(), id)
+	return cluster
+}
+
+// ClusterIDs returns the IDs of all stored clusters.
+func (f *fakeGuku) ClusterIDs() []string {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	ids := make([]string, 0, len(f.store.state.Clusters))
+	for id := range f.store.state.Clusters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// PlatformBindingIDs returns the IDs of all stored platform bindings.
+func (f *fakeGuku) PlatformBindingIDs() []string {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	ids := make([]string, 0, len(f.store.state.Bindings))
+	for id := range f.store.state.Bindings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// DeleteCluster removes a cluster and its platform bindings behind the
+// provider's back.
+func (f *fakeGuku) DeleteCluster(id string) {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	delete(f.store.state.Clusters, id)
+	for bindingID, binding := range f.store.state.Bindings {
+		if binding.ClusterID == id {
+			delete(f.store.state.Bindings, bindingID)
+		}
+	}
+}
+
+// DeletePlatformBinding removes a binding behind the provider's back.
+func (f *fakeGuku) DeletePlatformBinding(id string) {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	delete(f.store.state.Bindings, id)
+}
+
+func (f *fakeGuku) serveTokenExchange(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.PostForm.Get("grant_type") != tokenExchangeGrantType || r.PostForm.Get("subject_token") != fakeGukuOIDCToken {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusUnauthorized)
+		return
+	}
+
+	json.NewEncoder(w).Encode(tokenExchangeResponse{
+		AccessToken: fakeGukuAccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   3600,
+	})
+}
+
+func (f *fakeGuku) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	switch authorization := r.Header.Get("Authorization"); {
+	case authorization == "Bearer "+fakeGukuAPIToken, authorization == "Bearer "+fakeGukuAccessToken:
+	case f.cognito.ValidIDToken(authorization):
+	default:
+		http.Error(w, `{"errors":[{"errorType":"UnauthorizedException","message":"You are not authorized to make this call."}]}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req fakeGraphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	data, err := f.handle(req)
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		gqlErr := map[string]interface{}{"message": err.Error()}
+		var fakeErr *fakeGraphQLError
+		switch {
+		case errors.As(err, &fakeErr):
+			gqlErr["errorType"] = fakeErr.errorType
+		case IsNotFound(err):
+			gqlErr["errorType"] = notFoundErrorType
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":   nil,
+			"errors": []map[string]interface{}{gqlErr},
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{req.OperationName: data},
+	})
+}
+
+// handle runs an operation with f.mu held. Reads of missing objects return
+// null, as the guku API does.
+func (f *fakeGuku) handle(req fakeGraphQLRequest) (interface{}, error) {
+	f.requests[req.OperationName]++
+
+	if failures := f.failures[req.OperationName]; len(failures) > 0 {
+		f.failures[req.OperationName] = failures[1:]
+		return nil, failures[0]
+	}
+
+	var vars struct {
+		ID                string  `json:"id"`
+		Name              *string `json:"name"`
+		Server            *string `json:"server"`
+		CA                *string `json:"ca"`
+		Token             *string `json:"token"`
+		APIVersion        *string `json:"apiVersion"`
+		Context           *string `json:"context"`
+		ClusterID         string  `json:"clusterID"`
+		PlatformBindingID string  `json:"platformBindingID"`
+		PlatformID        string  `json:"platformID"`
+		PlatformVersion   *string `json:"platformVersion"`
+		PlatformConfigID  *string `json:"platformConfigID"`
+		Limit             *int    `json:"limit"`
+		NextToken         *string `json:"nextToken"`
+	}
+	if err := json.Unmarshal(req.Variables, &vars); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	switch req.OperationName {
+	case "getCluster":
+		cluster, err := f.store.GetCluster(ctx, vars.ID)
+		if err != nil {
+			return nil, err
+		}
+		if cluster == nil {
+			if deleting, ok := f.deleting[vars.ID]; ok && deleting.reads > 0 {
+				deleting.reads--
+				return deleting.cluster, nil
+			}
+			return nil, nil
+		}
+		if f.unregistered[vars.ID] > 0 {
+			f.unregistered[vars.ID]--
+			return &guku.Cluster{AccountID: cluster.AccountID, ClusterID: cluster.ClusterID, Name: cluster.Name}, nil
+		}
+		return cluster, nil
+
+	case "listCluster":
+		clusters, err := f.store.ListClusters(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// nextToken is the offset of the next page
+		start := 0
+		if vars.NextToken != nil {
+			start, err = strconv.Atoi(*vars.NextToken)
+			if err != nil || start < 0 || start > len(clusters) {
+				return nil, fmt.Errorf("invalid nextToken %q", *vars.NextToken)
+			}
+		}
+		end := len(clusters)
+		if vars.Limit != nil && start+*vars.Limit < end {
+			end = start + *vars.Limit
+		}
+		if f.listPageSize > 0 && start+f.listPageSize < end {
+			end = start + f.listPageSize
+		}
+
+		var nextToken *string
+		if end < len(clusters) {
+			token := strconv.Itoa(end)
+			nextToken = &token
+		}
+		return map[string]interface{}{"items": clusters[start:end], "nextToken": nextToken}, nil
+
+	case "createCluster":
+		clusterContext, err := normalizedJSON(vars.Context)
+		if err != nil {
+			return nil, err
+		}
+		created, err := f.store.CreateCluster(ctx, stringValue(vars.Name), stringValue(vars.Server), stringValue(vars.CA), stringValue(vars.Token), stringValue(vars.APIVersion), clusterContext)
+		if err != nil {
+			return nil, err
+		}
+		f.unregistered[created.ClusterID] = f.registrationReads
+		return created, nil
+
+	case "updateCluster":
+		clusterContext, err := normalizedJSON(vars.Context)
+		if err != nil {
+			return nil, err
+		}
+		return f.store.UpdateCluster(ctx, vars.ID, vars.Name, vars.Server, vars.CA, vars.Token, vars.APIVersion, clusterContext)
+
+	case "deleteCluster":
+		cluster, err := f.store.GetCluster(ctx, vars.ID)
+		if err != nil {
+			return nil, err
+		}
+		deleted, err := f.store.DeleteCluster(ctx, vars.ID)
+		if err != nil {
+			return nil, err
+		}
+		f.deleting[vars.ID] = &fakeDeletingCluster{cluster: cluster, reads: f.deletionReads}
+		return deleted, nil
+
+	case "getPlatform":
+		return f.store.GetPlatform(ctx, vars.PlatformID, stringValue(vars.PlatformVersion))
+
+	case "getPlatformBinding":
+		binding, err := f.store.GetPlatformBinding(ctx, vars.ClusterID, vars.PlatformBindingID)
+		if err != nil || binding == nil {
+			return nil, err
+		}
+		if statuses := f.transitions[vars.PlatformBindingID]; len(statuses) > 0 {
+			f.transitions[vars.PlatformBindingID] = statuses[1:]
+			binding.Status = f.setBindingStatus(vars.PlatformBindingID, statuses[0])
+		}
+		return binding, nil
+
+	case "createPlatformBinding":
+		created, err := f.store.CreatePlatformBinding(ctx, vars.ClusterID, vars.PlatformID, stringValue(vars.PlatformVersion), stringValue(vars.PlatformConfigID))
+		if err != nil {
+			return nil, err
+		}
+		created.Status = f.startTransition(created.PlatformBindingID)
+		return created, nil
+
+	case "updatePlatformBinding":
+		updated, err := f.store.UpdatePlatformBinding(ctx, vars.ClusterID, vars.PlatformBindingID, vars.PlatformConfigID, vars.PlatformVersion)
+		if err != nil {
+			return nil, err
+		}
+		updated.Status = f.startTransition(vars.PlatformBindingID)
+		return updated, nil
+
+	case "deletePlatformBinding":
+		delete(f.transitions, vars.PlatformBindingID)
+		return f.store.DeletePlatformBinding(ctx, vars.ClusterID, vars.PlatformBindingID)
+	}
+
+	return nil, fmt.Errorf("unsupported operation %q", req.OperationName)
+}
+
+// startTransition moves a created or updated binding to the first of
+// bindingStatuses and queues the others for its following reads.
+func (f *fakeGuku) startTransition(id string) guku.PlatformBindingStatus {
+	if len(f.bindingStatuses) == 0 {
+		delete(f.transitions, id)
+		return f.setBindingStatus(id, guku.PlatformBindingStatusSucceeded)
+	}
+	f.transitions[id] = append([]guku.PlatformBindingStatus{}, f.bindingStatuses[1:]...)
+	return f.setBindingStatus(id, f.bindingStatuses[0])
+}
+
+// setBindingStatus stores the status of a binding and returns it.
+func (f *fakeGuku) setBindingStatus(id string, status guku.PlatformBindingStatus) guku.PlatformBindingStatus {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	if binding, ok := f.store.state.Bindings[id]; ok {
+		binding.Status = status
+	}
+	return status
+}
+
+// normalizedJSON re-encodes AWSJSON values, which the guku API does not store
+// with the formatting and key order they were sent with. Like AppSync, it
+// rejects values that are not valid JSON, including empty strings.
+func normalizedJSON(val *string) (*string, error) {
+	if val == nil {
+		return nil, nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(*val), &decoded); err != nil {
+		return nil, fmt.Errorf("Variable 'context' has an invalid value: Unable to parse %q as valid JSON.", *val)
+	}
+	encoded, _ := json.Marshal(decoded)
+	normalized := string(encoded)
+	return &normalized, nil
+}
+
+func TestFakeGuku(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGuku(t)
+	fake.AddPlatform(guku.Platform{PlatformID: "platform-1", PlatformVersion: "v1", Name: "demo"})
+	fake.SetBindingStatuses(guku.PlatformBindingStatusPending, guku.PlatformBindingStatusSucceeded)
+
+	unauthorized := NewClient(fake.Endpoint(), &APITokenCredentials{Token: "wrong"}, ClientOptions{})
+	if _, err := unauthorized.GetCluster(ctx, "cluster-1"); err == nil {
+		t.Fatal("expected unauthorized error")
+	}
+
+	credentials, err := NewOIDCCredentials(ctx, http.DefaultClient, fake.TokenExchangeURL(), fakeGukuOIDCToken, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	client := NewClient(fake.Endpoint(), credentials, ClientOptions{})
+
+	clusterContext := "{}"
+	cluster, err := client.CreateCluster(ctx, "demo", "https://kubernetes", "ca", "token", "v1", &clusterContext)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	binding, err := client.CreatePlatformBinding(ctx, cluster.GetClusterID(), "platform-1", "v1", "config-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if binding.GetStatus() != guku.PlatformBindingStatusPending {
+		t.Errorf("expected Pending binding, got %s", binding.GetStatus())
+	}
+
+	for _, expected := range []guku.PlatformBindingStatus{guku.PlatformBindingStatusSucceeded, guku.PlatformBindingStatusSucceeded} {
+		pb, err := client.GetPlatformBinding(ctx, cluster.GetClusterID(), binding.GetPlatformBindingID())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if pb.GetStatus() != expected {
+			t.Errorf("expected %s binding, got %s", expected, pb.GetStatus())
+		}
+	}
+
+	if _, err := client.DeleteCluster(ctx, cluster.GetClusterID()); err == nil {
+		t.Error("expected cluster with bindings to not be deleted")
+	}
+
+	fake.DeletePlatformBinding(binding.GetPlatformBindingID())
+	if _, err := client.DeleteCluster(ctx, cluster.GetClusterID()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	read, err := client.GetCluster(ctx, cluster.GetClusterID())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if read != nil {
+		t.Errorf("expected deleted cluster to read as null, got %+v", read)
+	}
+}
+
+func TestFakeGuku_passwordLogin(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGuku(t)
+	clusterID := fake.AddCluster(guku.Cluster{Name: "demo"})
+
+	if _, err := CognitoLogin(ctx, fakeCognitoConfig, fake.Cognito().HTTPClient(), fakeGukuUsername, "wrong"); err == nil {
+		t.Fatal("expected error for incorrect password")
+	}
+
+	credentials, err := CognitoLogin(ctx, fakeCognitoConfig, fake.Cognito().HTTPClient(), fakeGukuUsername, fakeGukuPassword)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	client := NewClient(fake.Endpoint(), credentials, ClientOptions{})
+
+	cluster, err := client.GetCluster(ctx, clusterID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cluster == nil || cluster.GetName() != "demo" {
+		t.Errorf("expected cluster demo, got %+v", cluster)
+	}
+}
+
+func TestFakeGuku_optionalClusterAttributes(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGuku(t)
+	client := NewClient(fake.Endpoint(), &APITokenCredentials{Token: fakeGukuAPIToken}, ClientOptions{})
+
+	// like AppSync, the fake rejects AWSJSON values that are not JSON
+	empty := ""
+	if _, err := client.CreateCluster(ctx, "demo", "", "", "token", "v1", &empty); err == nil {
+		t.Fatal("expected error for empty context")
+	}
+
+	created, err := client.CreateCluster(ctx, "demo", "", "", "token", "v1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cluster, err := client.GetCluster(ctx, created.GetClusterID())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cluster.GetServer() == nil || *cluster.GetServer() != "" || cluster.GetCa() == nil || *cluster.GetCa() != "" {
+		t.Errorf("expected server and ca to be stored as sent, got %v and %v", cluster.GetServer(), cluster.GetCa())
+	}
+	if cluster.GetContext() != nil {
+		t.Errorf("expected null context, got %q", *cluster.GetContext())
+	}
+
+	if _, err := client.UpdateCluster(ctx, created.GetClusterID(), nil, nil, nil, nil, nil, &empty); err == nil {
+		t.Error("expected error for empty context")
+	}
+}
+
+func TestFakeGuku_listClusterPages(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGuku(t)
+	fake.SetListClusterPageSize(2)
+	for i := 0; i < 5; i++ {
+		fake.AddCluster(guku.Cluster{Name: fmt.Sprintf("cluster-%d", i)})
+	}
+
+	client := NewClient(fake.Endpoint(), &APITokenCredentials{Token: fakeGukuAPIToken}, ClientOptions{})
+	clusters, err := client.ListClusters(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var ids []string
+	for _, cluster := range clusters {
+		ids = append(ids, cluster.GetClusterID())
+	}
+	if !reflect.DeepEqual(ids, fake.ClusterIDs()) {
+		t.Errorf("expected clusters %v from all pages, got %v", fake.ClusterIDs(), ids)
+	}
+	if n := fake.Requests("listCluster"); n != 3 {
+		t.Errorf("expected 3 pages, got %d requests", n)
+	}
+}
@@ -0,0 +1,355 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/devopzilla/guku-client-go"
+)
+
+// MemoryEndpointPrefix selects the in-memory backend instead of the guku API.
+// It is followed by the path of the file the backend keeps its objects in
+// between Terraform commands, as in "memory://guku-state.json".
+const MemoryEndpointPrefix = "memory://"
+
+func isMemoryEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, MemoryEndpointPrefix)
+}
+
+// MemoryClient is a GukuClient that keeps clusters and platform bindings in
+// memory and serves platforms from a seeded catalog, so that configurations
+// can be tested without a guku account. IDs are assigned sequentially and
+// platform bindings succeed immediately, so runs are deterministic.
+type MemoryClient struct {
+	mu sync.Mutex
+	// path, when set, is the file objects are loaded from and saved to after
+	// every change.
+	path      string
+	state     memoryState
+	platforms []guku.Platform
+}
+
+type memoryState struct {
+	NextID   int                               `json:"nextID"`
+	Clusters map[string]*guku.Cluster          `json:"clusters"`
+	Bindings map[string]*memoryPlatformBinding `json:"bindings"`
+}
+
+type memoryPlatformBinding struct {
+	guku.PlatformBinding
+	ClusterID string `json:"clusterID"`
+}
+
+var _ GukuClient = &MemoryClient{}
+
+// NewMemoryClient returns a MemoryClient for a memory:// endpoint. catalog is
+// a JSON list of platforms in the format returned by the guku API, it may be
+// empty. Without a path, objects are only kept for the lifetime of the
+// client, as in tests.
+func NewMemoryClient(endpoint string, catalog string) (*MemoryClient, error) {
+	c := &MemoryClient{
+		path: strings.TrimPrefix(endpoint, MemoryEndpointPrefix),
+		state: memoryState{
+			Clusters: map[string]*guku.Cluster{},
+			Bindings: map[string]*memoryPlatformBinding{},
+		},
+	}
+
+	if strings.TrimSpace(catalog) != "" {
+		if err := json.Unmarshal([]byte(catalog), &c.platforms); err != nil {
+			return nil, fmt.Errorf("unable to parse platform catalog: %w", err)
+		}
+	}
+
+	if c.path != "" {
+		content, err := os.ReadFile(c.path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, err
+		default:
+			if err := json.Unmarshal(content, &c.state); err != nil {
+				return nil, fmt.Errorf("unable to parse %s: %w", c.path, err)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func (c *MemoryClient) GetCluster(ctx context.Context, id string) (*guku.Cluster, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cluster, ok := c.state.Clusters[id]
+	if !ok {
+		return nil, nil
+	}
+
+	result := *cluster
+	result.Bindings = []guku.PlatformBinding{}
+	for _, binding := range c.sortedBindings() {
+		if binding.ClusterID == id {
+			result.Bindings = append(result.Bindings, binding.PlatformBinding)
+		}
+	}
+	return &result, nil
+}
+
+func (c *MemoryClient) ListClusters(ctx context.Context) ([]*guku.Cluster, error) {
+	c.mu.Lock()
+	ids := make([]string, 0, len(c.state.Clusters))
+	for id := range c.state.Clusters {
+		ids = append(ids, id)
+	}
+	c.mu.Unlock()
+	sort.Strings(ids)
+
+	clusters := make([]*guku.Cluster, 0, len(ids))
+	for _, id := range ids {
+		cluster, err := c.GetCluster(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if cluster != nil {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
+func (c *MemoryClient) CreateCluster(ctx context.Context, name string, server string, ca string, token string, apiVersion string, clusterContext *string) (*guku.ClusterCreate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cluster := &guku.Cluster{
+		AccountID:  "memory",
+		ClusterID:  c.nextID("cluster"),
+		Name:       name,
+		Server:     &server,
+		Ca:         &ca,
+		ApiVersion: apiVersion,
+		Context:    clusterContext,
+	}
+	c.state.Clusters[cluster.ClusterID] = cluster
+
+	return &guku.ClusterCreate{ClusterID: cluster.ClusterID}, c.save()
+}
+
+func (c *MemoryClient) UpdateCluster(ctx context.Context, id string, name *string, server *string, ca *string, token *string, apiVersion *string, clusterContext *string) (*guku.ClusterUpdate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cluster, ok := c.state.Clusters[id]
+	if !ok {
+		return nil, &NotFoundError{Kind: "cluster", ID: id}
+	}
+
+	if name != nil {
+		cluster.Name = *name
+	}
+	if server != nil {
+		cluster.Server = server
+	}
+	if ca != nil {
+		cluster.Ca = ca
+	}
+	if apiVersion != nil {
+		cluster.ApiVersion = *apiVersion
+	}
+	if clusterContext != nil {
+		cluster.Context = clusterContext
+	}
+
+	return &guku.ClusterUpdate{ClusterID: id}, c.save()
+}
+
+func (c *MemoryClient) DeleteCluster(ctx context.Context, id string) (*guku.ClusterDelete, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.state.Clusters[id]; !ok {
+		return nil, &NotFoundError{Kind: "cluster", ID: id}
+	}
+	for _, binding := range c.state.Bindings {
+		if binding.ClusterID == id {
+			return nil, fmt.Errorf("cluster %s still has platform binding %s", id, binding.PlatformBindingID)
+		}
+	}
+
+	delete(c.state.Clusters, id)
+	return &guku.ClusterDelete{ClusterID: id}, c.save()
+}
+
+func (c *MemoryClient) GetPlatform(ctx context.Context, platformID string, platformVersion string) (*guku.Platform, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.platform(platformID, platformVersion), nil
+}
+
+func (c *MemoryClient) GetPlatformBinding(ctx context.Context, clusterID string, platformBindingID string) (*guku.PlatformBindingGet, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	binding, ok := c.state.Bindings[platformBindingID]
+	if !ok || binding.ClusterID != clusterID {
+		return nil, nil
+	}
+
+	return &guku.PlatformBindingGet{
+		PlatformConfigID: binding.PlatformConfigID,
+		PlatformID:       binding.PlatformID,
+		PlatformVersion:  binding.PlatformVersion,
+		Status:           binding.Status,
+	}, nil
+}
+
+func (c *MemoryClient) CreatePlatformBinding(ctx context.Context, clusterID string, platformID string, platformVersion string, platformConfigID string) (*guku.PlatformBindingCreate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.state.Clusters[clusterID]; !ok {
+		return nil, &NotFoundError{Kind: "cluster", ID: clusterID}
+	}
+	if err := c.validatePlatformConfig(platformID, platformVersion, platformConfigID); err != nil {
+		return nil, err
+	}
+
+	binding := &memoryPlatformBinding{
+		PlatformBinding: guku.PlatformBinding{
+			PlatformBindingID: c.nextID("binding"),
+			PlatformConfigID:  platformConfigID,
+			PlatformID:        platformID,
+			PlatformVersion:   platformVersion,
+			Status:            guku.PlatformBindingStatusSucceeded,
+		},
+		ClusterID: clusterID,
+	}
+	c.state.Bindings[binding.PlatformBindingID] = binding
+
+	return &guku.PlatformBindingCreate{
+		PlatformBindingID: binding.PlatformBindingID,
+		Status:            binding.Status,
+	}, c.save()
+}
+
+func (c *MemoryClient) UpdatePlatformBinding(ctx context.Context, clusterID string, platformBindingID string, platformConfigID *string, platformVersion *string) (*guku.PlatformBindingUpdate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	binding, ok := c.state.Bindings[platformBindingID]
+	if !ok || binding.ClusterID != clusterID {
+		return nil, &NotFoundError{Kind: "platform binding", ID: platformBindingID}
+	}
+
+	updated := binding.PlatformBinding
+	if platformConfigID != nil {
+		updated.PlatformConfigID = *platformConfigID
+	}
+	if platformVersion != nil {
+		updated.PlatformVersion = *platformVersion
+	}
+	if err := c.validatePlatformConfig(updated.PlatformID, updated.PlatformVersion, updated.PlatformConfigID); err != nil {
+		return nil, err
+	}
+	binding.PlatformBinding = updated
+
+	return &guku.PlatformBindingUpdate{
+		Status:           binding.Status,
+		PlatformConfigID: binding.PlatformConfigID,
+		PlatformVersion:  binding.PlatformVersion,
+	}, c.save()
+}
+
+func (c *MemoryClient) DeletePlatformBinding(ctx context.Context, clusterID string, platformBindingID string) (*guku.PlatformBindingDelete, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	binding, ok := c.state.Bindings[platformBindingID]
+	if !ok || binding.ClusterID != clusterID {
+		return nil, &NotFoundError{Kind: "platform binding", ID: platformBindingID}
+	}
+
+	delete(c.state.Bindings, platformBindingID)
+	return &guku.PlatformBindingDelete{PlatformID: binding.PlatformID}, c.save()
+}
+
+func (c *MemoryClient) nextID(prefix string) string {
+	c.state.NextID++
+	return fmt.Sprintf("%s-%d", prefix, c.state.NextID)
+}
+
+func (c *MemoryClient) platform(platformID string, platformVersion string) *guku.Platform {
+	for i := range c.platforms {
+		if c.platforms[i].PlatformID == platformID && c.platforms[i].PlatformVersion == platformVersion {
+			platform := c.platforms[i]
+			return &platform
+		}
+	}
+	return nil
+}
+
+// validatePlatformConfig checks that a binding refers to a platform in the
+// catalog and, when the platform lists configs, to one of them.
+func (c *MemoryClient) validatePlatformConfig(platformID string, platformVersion string, platformConfigID string) error {
+	platform := c.platform(platformID, platformVersion)
+	if platform == nil {
+		return fmt.Errorf("platform %s version %s not found in the memory catalog", platformID, platformVersion)
+	}
+	if len(platform.Configs) == 0 {
+		return nil
+	}
+
+	for _, config := range platform.Configs {
+		if config.PlatformConfigID == platformConfigID {
+			return nil
+		}
+	}
+	return fmt.Errorf("platform config %s not found in platform %s version %s", platformConfigID, platformID, platformVersion)
+}
+
+func (c *MemoryClient) sortedBindings() []*memoryPlatformBinding {
+	bindings := make([]*memoryPlatformBinding, 0, len(c.state.Bindings))
+	for _, binding := range c.state.Bindings {
+		bindings = append(bindings, binding)
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].PlatformBindingID < bindings[j].PlatformBindingID
+	})
+	return bindings
+}
+
+// save writes the objects to path, if set, with c.mu held.
+func (c *MemoryClient) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(c.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".guku-memory-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
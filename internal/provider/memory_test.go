@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testMemoryCatalog = `[
+  {
+    "platformID": "platform-1",
+    "platformVersion": "v1",
+    "name": "demo",
+    "minAPIVersion": "1.22",
+    "configs": [
+      {"platformConfigID": "config-1", "platformID": "platform-1", "platformVersion": "v1", "name": "default", "config": "{}"},
+      {"platformConfigID": "config-2", "platformID": "platform-1", "platformVersion": "v1", "name": "large", "config": "{}"}
+    ]
+  }
+]`
+
+func TestMemoryClient(t *testing.T) {
+	ctx := context.Background()
+	endpoint := MemoryEndpointPrefix + filepath.Join(t.TempDir(), "state.json")
+
+	client, err := NewMemoryClient(endpoint, testMemoryCatalog)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cluster, err := client.CreateCluster(ctx, "test", "", "", "token", "1.24", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cluster.ClusterID != "cluster-1" {
+		t.Errorf("expected cluster-1, got %s", cluster.ClusterID)
+	}
+
+	if _, err := client.CreatePlatformBinding(ctx, cluster.ClusterID, "platform-1", "v1", "config-3"); err == nil {
+		t.Error("expected error for a config missing from the catalog")
+	}
+	if _, err := client.CreatePlatformBinding(ctx, cluster.ClusterID, "platform-2", "v1", "config-1"); err == nil {
+		t.Error("expected error for a platform missing from the catalog")
+	}
+	binding, err := client.CreatePlatformBinding(ctx, cluster.ClusterID, "platform-1", "v1", "config-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// a second client picks up the objects saved by the first one
+	client, err = NewMemoryClient(endpoint, testMemoryCatalog)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := client.GetCluster(ctx, cluster.ClusterID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got == nil || got.Name != "test" || len(got.Bindings) != 1 || got.Bindings[0].PlatformBindingID != binding.PlatformBindingID {
+		t.Fatalf("expected saved cluster with one binding, got %+v", got)
+	}
+
+	if _, err := client.DeleteCluster(ctx, cluster.ClusterID); err == nil {
+		t.Error("expected error deleting a cluster with platform bindings")
+	}
+	if _, err := client.DeletePlatformBinding(ctx, cluster.ClusterID, binding.PlatformBindingID); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := client.DeleteCluster(ctx, cluster.ClusterID); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, _ := client.GetCluster(ctx, cluster.ClusterID); got != nil {
+		t.Errorf("expected deleted cluster to be missing, got %+v", got)
+	}
+
+	if _, err := NewMemoryClient(MemoryEndpointPrefix, "{"); err == nil {
+		t.Error("expected error for an invalid catalog")
+	}
+}
+
+func TestAccMemoryBackend(t *testing.T) {
+	providerConfig := fmt.Sprintf(`
+provider "guku" {
+  endpoint       = %q
+  memory_catalog = %q
+}
+`, MemoryEndpointPrefix+filepath.Join(t.TempDir(), "state.json"), testMemoryCatalog)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "guku_platform" "test" {
+  platform_id      = "platform-1"
+  platform_version = "v1"
+}
+
+resource "guku_cluster" "test" {
+  name        = "test"
+  token       = "service-account-token"
+  api_version = "1.24"
+}
+
+resource "guku_platform_binding" "test" {
+  cluster_id         = guku_cluster.test.id
+  platform_id        = data.guku_platform.test.platform_id
+  platform_version   = data.guku_platform.test.platform_version
+  platform_config_id = "config-1"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.guku_platform.test", "name", "demo"),
+					resource.TestCheckResourceAttr("guku_cluster.test", "id", "cluster-1"),
+					resource.TestCheckResourceAttr("guku_platform_binding.test", "id", "binding-2"),
+					resource.TestCheckResourceAttr("guku_platform_binding.test", "status", "Succeeded"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "guku_cluster" "test" {
+  name        = "test"
+  token       = "service-account-token"
+  api_version = "1.24"
+}
+
+resource "guku_platform_binding" "test" {
+  cluster_id         = guku_cluster.test.id
+  platform_id        = "platform-1"
+  platform_version   = "v1"
+  platform_config_id = "config-3"
+}
+`,
+				ExpectError: regexp.MustCompile("platform config config-3 not found"),
+			},
+		},
+	})
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	jwtTokenType           = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// OIDCCredentials authenticate by exchanging an OIDC workload identity token,
+// such as the one a CI system issues to each job, for a guku access token
+// using OAuth 2.0 token exchange (RFC 8693).
+type OIDCCredentials struct {
+	// ExchangeURL is the token exchange endpoint.
+	ExchangeURL string
+	// Token is the OIDC token. When empty it is read from TokenFile on every
+	// exchange, so that rotated tokens are picked up.
+	Token     string
+	TokenFile string
+
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+type tokenExchangeResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int32  `json:"expires_in"`
+}
+
+// NewOIDCCredentials returns OIDCCredentials after performing the first
+// exchange, so that configuration errors surface when the provider starts.
+func NewOIDCCredentials(ctx context.Context, httpClient *http.Client, exchangeURL string, token string, tokenFile string) (*OIDCCredentials, error) {
+	credentials := &OIDCCredentials{
+		ExchangeURL: exchangeURL,
+		Token:       token,
+		TokenFile:   tokenFile,
+		HTTPClient:  httpClient,
+	}
+	if err := credentials.exchange(ctx); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func (c *OIDCCredentials) Authorization(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken == "" || (!c.expiry.IsZero() && time.Now().Add(cognitoExpiryMargin).After(c.expiry)) {
+		if err := c.exchange(ctx); err != nil {
+			return "", err
+		}
+	}
+	return "Bearer " + c.accessToken, nil
+}
+
+func (c *OIDCCredentials) subjectToken() (string, error) {
+	if c.Token != "" {
+		return c.Token, nil
+	}
+
+	content, err := os.ReadFile(c.TokenFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func (c *OIDCCredentials) exchange(ctx context.Context) error {
+	subjectToken, err := c.subjectToken()
+	if err != nil {
+		return fmt.Errorf("unable to read OIDC token: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {jwtTokenType},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.ExchangeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to exchange OIDC token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to exchange OIDC token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to exchange OIDC token, got status %s: %s", resp.Status, body)
+	}
+
+	var result tokenExchangeResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("unable to parse OIDC token exchange response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return errors.New("unable to exchange OIDC token: no access token returned")
+	}
+
+	c.accessToken = result.AccessToken
+	c.expiry = time.Time{}
+	if result.ExpiresIn > 0 {
+		c.expiry = tokenExpiry(result.ExpiresIn)
+	}
+	return nil
+}
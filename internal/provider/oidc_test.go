@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestTokenExchangeServer stands in for an OIDC token exchange endpoint.
+// It issues "access-<subject token>" for every subject token it accepts.
+func newTestTokenExchangeServer(t *testing.T, expiresIn int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("grant_type") != tokenExchangeGrantType || r.PostForm.Get("subject_token_type") != jwtTokenType {
+			http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("subject_token") == "rejected" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusUnauthorized)
+			return
+		}
+
+		json.NewEncoder(w).Encode(tokenExchangeResponse{
+			AccessToken: "access-" + r.PostForm.Get("subject_token"),
+			TokenType:   "Bearer",
+			ExpiresIn:   expiresIn,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOIDCCredentials(t *testing.T) {
+	server := newTestTokenExchangeServer(t, 3600)
+
+	credentials, err := NewOIDCCredentials(context.Background(), http.DefaultClient, server.URL, "ci-job-token", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	authorization, err := credentials.Authorization(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if authorization != "Bearer access-ci-job-token" {
+		t.Errorf("expected exchanged token, got %q", authorization)
+	}
+}
+
+func TestOIDCCredentials_tokenFileRotation(t *testing.T) {
+	// tokens that expire immediately are exchanged again on every request
+	server := newTestTokenExchangeServer(t, 1)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials, err := NewOIDCCredentials(context.Background(), http.DefaultClient, server.URL, "", tokenFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := os.WriteFile(tokenFile, []byte("second\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	authorization, err := credentials.Authorization(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if authorization != "Bearer access-second" {
+		t.Errorf("expected rotated token to be exchanged, got %q", authorization)
+	}
+}
+
+func TestOIDCCredentials_rejected(t *testing.T) {
+	server := newTestTokenExchangeServer(t, 3600)
+
+	if _, err := NewOIDCCredentials(context.Background(), http.DefaultClient, server.URL, "rejected", ""); err == nil {
+		t.Fatal("expected error for rejected token")
+	}
+}
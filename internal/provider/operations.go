@@ -0,0 +1,150 @@
+package provider
+
+// GraphQL operations issued by Client. These mirror the operations generated
+// in github.com/devopzilla/guku-client-go so responses decode into its types.
+
+const (
+	getClusterOperation = `
+query getCluster ($id: ID!) {
+	getCluster(clusterID: $id) {
+		accountID
+		ca
+		clusterID
+		name
+		server
+		privateTunnelToken
+		apiVersion
+		context
+		bindings {
+			platformBindingID
+			platformConfigID
+			platformID
+			platformVersion
+			status
+		}
+	}
+}
+`
+
+	listClusterOperation = `
+query listCluster ($limit: Int, $nextToken: String) {
+	listCluster(limit: $limit, nextToken: $nextToken) {
+		items {
+			accountID
+			ca
+			clusterID
+			name
+			server
+			privateTunnelToken
+			apiVersion
+			context
+			bindings {
+				platformBindingID
+				platformConfigID
+				platformID
+				platformVersion
+				status
+			}
+		}
+		nextToken
+	}
+}
+`
+
+	createClusterOperation = `
+mutation createCluster ($name: String!, $server: String!, $ca: String!, $token: String!, $apiVersion: String!, $context: AWSJSON) {
+	createCluster(input: {name:$name,server:$server,ca:$ca,token:$token,apiVersion:$apiVersion,context:$context}) {
+		clusterID
+	}
+}
+`
+
+	updateClusterOperation = `
+mutation updateCluster ($id: ID!, $name: String, $server: String, $ca: String, $token: String, $apiVersion: String, $context: AWSJSON) {
+	updateCluster(input: {clusterID:$id,name:$name,server:$server,ca:$ca,token:$token,apiVersion:$apiVersion,context:$context}) {
+		clusterID
+	}
+}
+`
+
+	deleteClusterOperation = `
+mutation deleteCluster ($id: ID!) {
+	deleteCluster(input: {clusterID:$id}) {
+		clusterID
+	}
+}
+`
+
+	getPlatformOperation = `
+query getPlatform ($platformID: ID!, $platformVersion: String!) {
+	getPlatform(platformID: $platformID, platformVersion: $platformVersion) {
+		accountID
+		catalogedDate
+		description
+		name
+		platformID
+		configs {
+			accountID
+			config
+			name
+			platformConfigID
+			platformID
+			platformVersion
+		}
+		platformVersion
+		services {
+			accountID
+			delete_dependencies
+			dependencies
+			name
+			namespace
+			platformID
+			platformServiceID
+			platformVersion
+			serviceID
+			serviceVersion
+		}
+		minAPIVersion
+		maxAPIVersion
+	}
+}
+`
+
+	getPlatformBindingOperation = `
+query getPlatformBinding ($clusterID: ID!, $platformBindingID: ID!) {
+	getPlatformBinding(clusterID: $clusterID, platformBindingID: $platformBindingID) {
+		platformConfigID
+		platformID
+		platformVersion
+		status
+	}
+}
+`
+
+	createPlatformBindingOperation = `
+mutation createPlatformBinding ($platformVersion: String!, $platformID: ID!, $platformConfigID: ID!, $clusterID: ID!) {
+	createPlatformBinding(input: {platformVersion:$platformVersion,platformID:$platformID,platformConfigID:$platformConfigID,clusterID:$clusterID}) {
+		platformBindingID
+		status
+	}
+}
+`
+
+	updatePlatformBindingOperation = `
+mutation updatePlatformBinding ($clusterID: ID!, $platformBindingID: ID!, $platformConfigID: ID, $platformVersion: String) {
+	updatePlatformBinding(input: {clusterID:$clusterID,platformBindingID:$platformBindingID,platformConfigID:$platformConfigID,platformVersion:$platformVersion}) {
+		status
+		platformConfigID
+		platformVersion
+	}
+}
+`
+
+	deletePlatformBindingOperation = `
+mutation deletePlatformBinding ($clusterID: ID!, $platformBindingID: ID!) {
+	deletePlatformBinding(input: {clusterID:$clusterID,platformBindingID:$platformBindingID}) {
+		platformID
+	}
+}
+`
+)
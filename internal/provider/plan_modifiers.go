@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ tfsdk.AttributePlanModifier = jsonSemanticEqualityModifier{}
+
+// jsonSemanticEqualityModifier plans the prior state of a JSON string
+// attribute when the configuration holds the same JSON value, so that
+// formatting and key order changes show no diff.
+type jsonSemanticEqualityModifier struct{}
+
+func (m jsonSemanticEqualityModifier) Description(ctx context.Context) string {
+	return "Keeps the prior value if the configured JSON is semantically equal to it."
+}
+
+func (m jsonSemanticEqualityModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m jsonSemanticEqualityModifier) Modify(ctx context.Context, req tfsdk.ModifyAttributePlanRequest, resp *tfsdk.ModifyAttributePlanResponse) {
+	var config, state types.String
+
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeConfig, &config)...)
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeState, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.IsNull() || config.IsUnknown() || state.IsNull() || state.IsUnknown() {
+		return
+	}
+
+	if config.Value != state.Value && JSONSemanticallyEqual(config.Value, state.Value) {
+		resp.AttributePlan = state
+	}
+}
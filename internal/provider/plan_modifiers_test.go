@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestJSONSemanticEqualityModifier(t *testing.T) {
+	testCases := map[string]struct {
+		config   types.String
+		state    types.String
+		expected types.String
+	}{
+		"reformatted": {
+			config:   types.String{Value: "{\n  \"b\": [1, 2],\n  \"a\": {\"c\": null}\n}\n"},
+			state:    types.String{Value: `{"a":{"c":null},"b":[1,2]}`},
+			expected: types.String{Value: `{"a":{"c":null},"b":[1,2]}`},
+		},
+		"changed": {
+			config:   types.String{Value: `{"a":2}`},
+			state:    types.String{Value: `{"a":1}`},
+			expected: types.String{Value: `{"a":2}`},
+		},
+		"invalid": {
+			config:   types.String{Value: `{"a":`},
+			state:    types.String{Value: `{"a":1}`},
+			expected: types.String{Value: `{"a":`},
+		},
+		"created": {
+			config:   types.String{Value: `{"a":1}`},
+			state:    types.String{Null: true},
+			expected: types.String{Value: `{"a":1}`},
+		},
+		"removed": {
+			config:   types.String{Null: true},
+			state:    types.String{Value: `{"a":1}`},
+			expected: types.String{Null: true},
+		},
+		"unknown": {
+			config:   types.String{Unknown: true},
+			state:    types.String{Value: `{"a":1}`},
+			expected: types.String{Unknown: true},
+		},
+	}
+
+	for name, testCase := range testCases {
+		req := tfsdk.ModifyAttributePlanRequest{
+			AttributeConfig: testCase.config,
+			AttributeState:  testCase.state,
+			AttributePlan:   testCase.config,
+		}
+		resp := &tfsdk.ModifyAttributePlanResponse{
+			AttributePlan: req.AttributePlan,
+		}
+
+		jsonSemanticEqualityModifier{}.Modify(context.Background(), req, resp)
+
+		if resp.Diagnostics.HasError() {
+			t.Errorf("%s: unexpected error: %v", name, resp.Diagnostics)
+			continue
+		}
+		if !resp.AttributePlan.Equal(testCase.expected) {
+			t.Errorf("%s: expected plan %s, got %s", name, testCase.expected, resp.AttributePlan)
+		}
+	}
+}
@@ -24,7 +24,8 @@ func NewPlatformBindingResource() resource.Resource {
 
 // PlatformBindingResource defines the resource implementation.
 type PlatformBindingResource struct {
-	client *guku.Client
+	client GukuClient
+	waiter *Waiter
 }
 
 // PlatformBindingResourceModel describes the resource data model.
@@ -35,6 +36,8 @@ type PlatformBindingResourceModel struct {
 	PlatformID        types.String `tfsdk:"platform_id"`
 	PlatformVersion   types.String `tfsdk:"platform_version"`
 	Status            types.String `tfsdk:"status"`
+
+	Timeouts *TimeoutsModel `tfsdk:"timeouts"`
 }
 
 func (r *PlatformBindingResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -42,6 +45,8 @@ func (r *PlatformBindingResource) Metadata(ctx context.Context, req resource.Met
 }
 
 func (r *PlatformBindingResource) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	pollingTimeout := fmt.Sprintf("the time taken by `polling.max_attempts` polls, `%s` with the default `polling`", DefaultPollPolicy.Timeout())
+
 	return tfsdk.Schema{
 		// This description is used by the documentation generator and the language server.
 		MarkdownDescription: "PlatformBinding resource",
@@ -81,6 +86,9 @@ func (r *PlatformBindingResource) GetSchema(ctx context.Context) (tfsdk.Schema,
 				Type:                types.StringType,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(pollingTimeout, pollingTimeout, fmt.Sprintf("`%s`", platformBindingDeleteTimeout)),
+		},
 	}, nil
 }
 
@@ -90,18 +98,19 @@ func (r *PlatformBindingResource) Configure(ctx context.Context, req resource.Co
 		return
 	}
 
-	client, ok := req.ProviderData.(*guku.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *guku.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.waiter = data.Waiter
 }
 
 func (r *PlatformBindingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -114,44 +123,51 @@ func (r *PlatformBindingResource) Create(ctx context.Context, req resource.Creat
 		return
 	}
 
+	createTimeout, diags := data.Timeouts.CreateTimeout(r.waiter.Policy.Timeout())
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	platformBinding, err := r.client.CreatePlatformBinding(
+		ctx,
 		data.ClusterID.Value,
 		data.PlatformID.Value,
 		data.PlatformVersion.Value,
 		data.PlatformConfigID.Value,
 	)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create platform binding, got error: %s", err))
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to create platform binding", err))
 		return
 	}
 
 	data.PlatformBindingID = types.String{Value: platformBinding.GetPlatformBindingID()}
 
 	// poll till status is not pending
-	attempts := 1
-	status := string(platformBinding.GetStatus())
-	for status == string(guku.PlatformBindingStatusPending) && attempts <= 20 {
-		tflog.Trace(ctx, fmt.Sprintf("Polling platform binding %s attempt number %d", data.PlatformBindingID.Value, attempts))
-
-		pb, err := r.client.GetPlatformBinding(data.ClusterID.Value, data.PlatformBindingID.Value)
-		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to poll platform binding, got error: %s", err))
-			return
-		}
-
-		status = string(pb.GetStatus())
-
-		attempts++
-		time.Sleep(time.Second * 30)
+	status, err := r.waitForStatus(ctx, data, platformBinding.GetStatus(), createTimeout)
+	if err != nil {
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to poll platform binding", err))
+		// the binding exists, save it so that it is tainted rather than lost
+		data.Status = types.String{Value: string(status)}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
 	}
 
 	// fail if status is not succeeded
-	if status != string(guku.PlatformBindingStatusSucceeded) {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create platform binding, got status: %s", status))
+	if status != guku.PlatformBindingStatusSucceeded {
+		resp.Diagnostics.AddError(
+			"Client Error",
+			fmt.Sprintf("Unable to create platform binding, got status: %s. "+
+				"It was saved as tainted and will be replaced on the next apply.", status),
+		)
+		// the binding exists, save it so that it is tainted rather than lost
+		data.Status = types.String{Value: string(status)}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 		return
 	}
 
-	data.Status = types.String{Value: status}
+	data.Status = types.String{Value: string(status)}
 
 	// Write logs using the tflog package
 	// Documentation: https://terraform.io/plugin/log
@@ -172,11 +188,18 @@ func (r *PlatformBindingResource) Read(ctx context.Context, req resource.ReadReq
 	}
 
 	platformBinding, err := r.client.GetPlatformBinding(
+		ctx,
 		data.ClusterID.Value,
 		data.PlatformBindingID.Value,
 	)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read platform binding, got error: %s", err))
+	if err != nil && !IsNotFound(err) {
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to read platform binding", err))
+		return
+	}
+	if platformBinding == nil {
+		// deleted outside of terraform, plan to create it again
+		tflog.Warn(ctx, fmt.Sprintf("Platform binding %s was not found, removing it from state", data.PlatformBindingID.Value))
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
@@ -199,7 +222,15 @@ func (r *PlatformBindingResource) Update(ctx context.Context, req resource.Updat
 		return
 	}
 
+	updateTimeout, diags := data.Timeouts.UpdateTimeout(r.waiter.Policy.Timeout())
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	platformBinding, err := r.client.UpdatePlatformBinding(
+		ctx,
 		data.ClusterID.Value,
 		data.PlatformBindingID.Value,
 		ValueStringOrNull(data.PlatformConfigID),
@@ -207,35 +238,24 @@ func (r *PlatformBindingResource) Update(ctx context.Context, req resource.Updat
 	)
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update platform binding, got error: %s", err))
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to update platform binding", err))
 		return
 	}
 
 	// poll till status is not pending
-	attempts := 1
-	status := string(platformBinding.GetStatus())
-	for status == string(guku.PlatformBindingStatusPending) && attempts <= 20 {
-		tflog.Trace(ctx, fmt.Sprintf("Polling platform binding %s attempt number %d", data.PlatformBindingID.Value, attempts))
-
-		pb, err := r.client.GetPlatformBinding(data.ClusterID.Value, data.PlatformBindingID.Value)
-		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to poll platform binding, got error: %s", err))
-			return
-		}
-
-		status = string(pb.GetStatus())
-
-		attempts++
-		time.Sleep(time.Second * 30)
+	status, err := r.waitForStatus(ctx, data, platformBinding.GetStatus(), updateTimeout)
+	if err != nil {
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to poll platform binding", err))
+		return
 	}
 
 	// fail if status is not succeeded
-	if status != string(guku.PlatformBindingStatusSucceeded) {
+	if status != guku.PlatformBindingStatusSucceeded {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update platform binding, got status: %s", status))
 		return
 	}
 
-	data.Status = types.String{Value: status}
+	data.Status = types.String{Value: string(status)}
 
 	tflog.Trace(ctx, "updated a platform binding")
 
@@ -253,21 +273,92 @@ func (r *PlatformBindingResource) Delete(ctx context.Context, req resource.Delet
 		return
 	}
 
+	deleteTimeout, diags := data.Timeouts.DeleteTimeout(platformBindingDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	_, err := r.client.DeletePlatformBinding(
+		ctx,
 		data.ClusterID.Value,
 		data.PlatformBindingID.Value,
 	)
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete platform binding, got error: %s", err))
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to delete platform binding", err))
 		return
 	}
 
-	time.Sleep(time.Minute * 2)
+	deleted, err := r.waitForDeletion(ctx, data, deleteTimeout)
+	if err != nil {
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to wait for platform binding deletion", err))
+		return
+	}
+	if !deleted && data.Timeouts.DeleteSet() {
+		resp.Diagnostics.AddError(
+			"Platform Binding Not Deleted",
+			fmt.Sprintf("Platform binding %s was deleted, but the guku API still reported it after %s. "+
+				"Increase timeouts.delete if the guku API is slow to uninstall platforms.", data.PlatformBindingID.Value, deleteTimeout),
+		)
+		return
+	}
+	if !deleted {
+		// the guku API accepted the deletion, which it finishes once the
+		// platform is uninstalled
+		resp.Diagnostics.AddWarning(
+			"Platform Binding Not Deleted Yet",
+			fmt.Sprintf("Platform binding %s was deleted, but the guku API still reported it after %s. "+
+				"It was removed from the state, and set timeouts.delete to wait longer for the guku API to uninstall platforms.", data.PlatformBindingID.Value, deleteTimeout),
+		)
+	}
 
 	tflog.Trace(ctx, "deleted a platform binding")
 }
 
+// waitForStatus polls the platform binding until its status is no longer
+// Pending, or the timeout passes, and returns the last status.
+func (r *PlatformBindingResource) waitForStatus(ctx context.Context, data *PlatformBindingResourceModel, status guku.PlatformBindingStatus, timeout time.Duration) (guku.PlatformBindingStatus, error) {
+	if status != guku.PlatformBindingStatusPending {
+		return status, nil
+	}
+
+	_, err := r.waiter.PollUntil(ctx, timeout, func(ctx context.Context, poll int) (bool, error) {
+		tflog.Trace(ctx, fmt.Sprintf("Polling platform binding %s attempt number %d", data.PlatformBindingID.Value, poll))
+
+		pb, err := r.client.GetPlatformBinding(ctx, data.ClusterID.Value, data.PlatformBindingID.Value)
+		if err != nil && !IsNotFound(err) {
+			return false, err
+		}
+		if pb == nil {
+			// deleted while it is being polled
+			return false, &NotFoundError{Kind: "platform binding", ID: data.PlatformBindingID.Value}
+		}
+
+		status = pb.GetStatus()
+		return status != guku.PlatformBindingStatusPending, nil
+	})
+	return status, err
+}
+
+// waitForDeletion polls the platform binding until the guku API no longer
+// reports it, which happens once the platform was uninstalled.
+func (r *PlatformBindingResource) waitForDeletion(ctx context.Context, data *PlatformBindingResourceModel, timeout time.Duration) (bool, error) {
+	return r.waiter.PollUntil(ctx, timeout, func(ctx context.Context, poll int) (bool, error) {
+		tflog.Trace(ctx, fmt.Sprintf("Polling deleted platform binding %s attempt number %d", data.PlatformBindingID.Value, poll))
+
+		pb, err := r.client.GetPlatformBinding(ctx, data.ClusterID.Value, data.PlatformBindingID.Value)
+		if IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return pb == nil, nil
+	})
+}
+
 func (r *PlatformBindingResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
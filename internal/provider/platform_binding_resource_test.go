@@ -0,0 +1,370 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/devopzilla/guku-client-go"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccPlatformBindingResource_create(t *testing.T) {
+	testCases := map[string]struct {
+		statuses    []guku.PlatformBindingStatus
+		expectError *regexp.Regexp
+		waits       int
+	}{
+		"immediate success": {
+			statuses: []guku.PlatformBindingStatus{guku.PlatformBindingStatusSucceeded},
+		},
+		"eventual success": {
+			statuses: []guku.PlatformBindingStatus{guku.PlatformBindingStatusPending, guku.PlatformBindingStatusPending, guku.PlatformBindingStatusSucceeded},
+			waits:    1,
+		},
+		"failed": {
+			statuses:    []guku.PlatformBindingStatus{guku.PlatformBindingStatusPending, guku.PlatformBindingStatusFailed},
+			expectError: regexp.MustCompile("Unable to create platform binding, got status: Failed"),
+		},
+		"error": {
+			statuses:    []guku.PlatformBindingStatus{guku.PlatformBindingStatusError},
+			expectError: regexp.MustCompile("Unable to create platform binding, got status: Error"),
+		},
+		// a binding that stays Pending is polled 20 times, with a wait
+		// after every poll until the timeout passes
+		"poll timeout": {
+			statuses:    []guku.PlatformBindingStatus{guku.PlatformBindingStatusPending},
+			expectError: regexp.MustCompile("Unable to create platform binding, got status: Pending"),
+			waits:       20,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			fake, clusterID := newFakeGukuWithPlatform(t)
+			fake.SetBindingStatuses(testCase.statuses...)
+			clock := &fakeClock{}
+
+			step := resource.TestStep{
+				Config:      fake.ProviderConfig() + testAccPlatformBindingResourceConfig(clusterID, "config-1"),
+				ExpectError: testCase.expectError,
+			}
+			if testCase.expectError == nil {
+				step.Check = resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("guku_platform_binding.test", "status", "Succeeded"),
+					resource.TestCheckResourceAttr("guku_platform_binding.test", "cluster_id", clusterID),
+					resource.TestCheckResourceAttr("guku_platform_binding.test", "platform_config_id", "config-1"),
+					resource.TestCheckResourceAttrSet("guku_platform_binding.test", "id"),
+				)
+			}
+
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(clock),
+				// bindings that did not succeed are saved as tainted, so they
+				// are destroyed with the rest of the state rather than lost
+				CheckDestroy: testAccCheckPlatformBindingsDestroyed(fake),
+				Steps:        []resource.TestStep{step},
+			})
+
+			if waits := clock.Count(DefaultPollPolicy.Interval); waits != testCase.waits {
+				t.Errorf("expected %d waits between polls, got %d", testCase.waits, waits)
+			}
+		})
+	}
+}
+
+func TestAccPlatformBindingResource_update(t *testing.T) {
+	fake, clusterID := newFakeGukuWithPlatform(t)
+	var bindingID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(&fakeClock{}),
+		CheckDestroy:             testAccCheckPlatformBindingsDestroyed(fake),
+		Steps: []resource.TestStep{
+			{
+				Config: fake.ProviderConfig() + testAccPlatformBindingResourceConfig(clusterID, "config-1"),
+				Check: func(s *terraform.State) error {
+					bindingID = s.RootModule().Resources["guku_platform_binding.test"].Primary.ID
+					return nil
+				},
+			},
+			// eventual success
+			{
+				PreConfig: func() {
+					fake.SetBindingStatuses(guku.PlatformBindingStatusPending, guku.PlatformBindingStatusSucceeded)
+				},
+				Config: fake.ProviderConfig() + testAccPlatformBindingResourceConfig(clusterID, "config-2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("guku_platform_binding.test", "status", "Succeeded"),
+					resource.TestCheckResourceAttr("guku_platform_binding.test", "platform_config_id", "config-2"),
+					func(s *terraform.State) error {
+						if id := s.RootModule().Resources["guku_platform_binding.test"].Primary.ID; id != bindingID {
+							return fmt.Errorf("expected binding %s to be updated in place, got %s", bindingID, id)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				PreConfig: func() {
+					fake.SetBindingStatuses(guku.PlatformBindingStatusPending, guku.PlatformBindingStatusFailed)
+				},
+				Config:      fake.ProviderConfig() + testAccPlatformBindingResourceConfig(clusterID, "config-3"),
+				ExpectError: regexp.MustCompile("Unable to update platform binding, got status: Failed"),
+			},
+		},
+	})
+}
+
+func TestAccPlatformBindingResource_pollError(t *testing.T) {
+	fake, clusterID := newFakeGukuWithPlatform(t)
+	fake.SetBindingStatuses(guku.PlatformBindingStatusPending)
+
+	clock := &fakeClock{
+		onSleep: func(n int) {
+			if n == 2 {
+				fake.Fail("getPlatformBinding", "UnauthorizedException", "Unauthorized: token revoked")
+			}
+		},
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(clock),
+		// the binding is saved to state, so that it is destroyed
+		CheckDestroy: testAccCheckPlatformBindingsDestroyed(fake),
+		Steps: []resource.TestStep{
+			{
+				Config:      fake.ProviderConfig() + testAccPlatformBindingResourceConfig(clusterID, "config-1"),
+				ExpectError: regexp.MustCompile("Unable to poll platform binding, got error: .*token revoked"),
+			},
+		},
+	})
+
+	if waits := clock.Count(DefaultPollPolicy.Interval); waits != 2 {
+		t.Errorf("expected 2 waits before the error, got %d", waits)
+	}
+}
+
+func TestAccPlatformBindingResource_timeouts(t *testing.T) {
+	fake, clusterID := newFakeGukuWithPlatform(t)
+	clock := &fakeClock{}
+
+	config := func(create string, update string) string {
+		return fake.ProviderConfig() + fmt.Sprintf(`
+resource "guku_platform_binding" "test" {
+  cluster_id         = %q
+  platform_id        = "platform-1"
+  platform_version   = "v1"
+  platform_config_id = "config-1"
+
+  timeouts {
+    create = %q
+    update = %q
+  }
+}
+`, clusterID, create, update)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(clock),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					fake.SetBindingStatuses(guku.PlatformBindingStatusPending)
+				},
+				Config:      config("1m", "1m"),
+				ExpectError: regexp.MustCompile("Unable to create platform binding, got status: Pending"),
+			},
+			{
+				PreConfig: func() {
+					fake.SetBindingStatuses(guku.PlatformBindingStatusSucceeded)
+				},
+				Config:      config("1m", "soon"),
+				ExpectError: regexp.MustCompile("timeouts.update must be a positive duration"),
+			},
+		},
+	})
+
+	// polls at 0s and 30s, the timeout passes during the second wait
+	if waits := clock.Count(DefaultPollPolicy.Interval); waits != 2 {
+		t.Errorf("expected 2 waits within the create timeout, got %d", waits)
+	}
+}
+
+func TestPlatformBindingResourceWaitForStatus_deleted(t *testing.T) {
+	fake, clusterID := newFakeGukuWithPlatform(t)
+	fake.SetBindingStatuses(guku.PlatformBindingStatusPending)
+
+	client := NewClient(fake.Endpoint(), &APITokenCredentials{Token: fakeGukuAPIToken}, ClientOptions{})
+	binding, err := client.CreatePlatformBinding(context.Background(), clusterID, "platform-1", "v1", "config-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// deleted while it is being polled
+	fake.DeletePlatformBinding(binding.GetPlatformBindingID())
+
+	r := &PlatformBindingResource{client: client, waiter: &Waiter{Clock: &fakeClock{}, Policy: DefaultPollPolicy}}
+	data := &PlatformBindingResourceModel{
+		ClusterID:         types.String{Value: clusterID},
+		PlatformBindingID: types.String{Value: binding.GetPlatformBindingID()},
+	}
+
+	status, err := r.waitForStatus(context.Background(), data, binding.GetStatus(), time.Minute)
+	if !IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if status != guku.PlatformBindingStatusPending {
+		t.Errorf("expected last known status Pending, got %s", status)
+	}
+}
+
+// newFakeGukuWithPlatform returns a fakeGuku with a cluster and a platform to
+// bind to it.
+func newFakeGukuWithPlatform(t *testing.T) (*fakeGuku, string) {
+	fake := newFakeGuku(t)
+	fake.AddPlatform(guku.Platform{PlatformID: "platform-1", PlatformVersion: "v1", Name: "demo"})
+	clusterID := fake.AddCluster(guku.Cluster{Name: "test", ApiVersion: "1.24"})
+	return fake, clusterID
+}
+
+func TestAccPlatformBindingResource_deletedOutOfBand(t *testing.T) {
+	testCases := map[string]struct {
+		deleted     bool
+		failureType string
+		failure     string
+		expectError *regexp.Regexp
+	}{
+		"null": {
+			deleted: true,
+		},
+		"not found error": {
+			deleted:     true,
+			failureType: notFoundErrorType,
+			failure:     "Platform binding not found",
+		},
+		"other error": {
+			failureType: "UnauthorizedException",
+			failure:     "Unauthorized: token revoked",
+			expectError: regexp.MustCompile("Unable to read platform binding, got error: .*token revoked"),
+		},
+		// only the not found error type of the guku API is taken as deleted
+		"other error mentioning not found": {
+			failureType: "Lambda:Unhandled",
+			failure:     "Platform config not found",
+			expectError: regexp.MustCompile("Unable to read platform binding, got error: .*Platform config not found"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			fake, clusterID := newFakeGukuWithPlatform(t)
+			var bindingIDs []string
+
+			config := fake.ProviderConfig() + testAccPlatformBindingResourceConfig(clusterID, "config-1")
+
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithClock(&fakeClock{}),
+				CheckDestroy:             testAccCheckPlatformBindingsDestroyed(fake),
+				Steps: []resource.TestStep{
+					{
+						Config: config,
+						Check: func(s *terraform.State) error {
+							bindingIDs = fake.PlatformBindingIDs()
+							return nil
+						},
+					},
+					// the binding is created again, unless reading it failed
+					{
+						PreConfig: func() {
+							if testCase.deleted {
+								fake.DeletePlatformBinding(bindingIDs[0])
+							}
+							if testCase.failure != "" {
+								fake.Fail("getPlatformBinding", testCase.failureType, testCase.failure)
+							}
+						},
+						Config:      config,
+						ExpectError: testCase.expectError,
+						Check: func(s *terraform.State) error {
+							id := s.RootModule().Resources["guku_platform_binding.test"].Primary.ID
+							if ids := fake.PlatformBindingIDs(); len(ids) != 1 || ids[0] != id || id == bindingIDs[0] {
+								return fmt.Errorf("expected platform binding %s to be created again, got %s in state and %v", bindingIDs[0], id, ids)
+							}
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+func testAccPlatformBindingResourceConfig(clusterID string, platformConfigID string) string {
+	return fmt.Sprintf(`
+resource "guku_platform_binding" "test" {
+  cluster_id         = %q
+  platform_id        = "platform-1"
+  platform_version   = "v1"
+  platform_config_id = %q
+}
+`, clusterID, platformConfigID)
+}
+
+func testAccCheckPlatformBindingsDestroyed(fake *fakeGuku) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if ids := fake.PlatformBindingIDs(); len(ids) > 0 {
+			return fmt.Errorf("expected all platform bindings to be destroyed, found %v", ids)
+		}
+		return nil
+	}
+}
+
+func TestAccPlatformBindingResource_recorded(t *testing.T) {
+	recorder := newRecorder(t)
+
+	config := recorder.ProviderConfig() + fmt.Sprintf(`
+resource "guku_cluster" "test" {
+  name        = "tf-acc-binding"
+  token       = "service-account-token"
+  api_version = "1.24"
+}
+
+resource "guku_platform_binding" "test" {
+  cluster_id         = guku_cluster.test.id
+  platform_id        = %q
+  platform_version   = %q
+  platform_config_id = %q
+}
+`,
+		recorder.Value("platform_id", "GUKU_TEST_PLATFORM_ID"),
+		recorder.Value("platform_version", "GUKU_TEST_PLATFORM_VERSION"),
+		recorder.Value("platform_config_id", "GUKU_TEST_PLATFORM_CONFIG_ID"),
+	)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: recorder.ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("guku_platform_binding.test", "status", "Succeeded"),
+					resource.TestCheckResourceAttrPair("guku_platform_binding.test", "cluster_id", "guku_cluster.test", "id"),
+				),
+			},
+		},
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/devopzilla/guku-client-go"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -23,7 +22,7 @@ func NewPlatformDataSource() datasource.DataSource {
 
 // PlatformDataSource defines the data source implementation.
 type PlatformDataSource struct {
-	client *guku.Client
+	client GukuClient
 }
 
 // PlatformDataSourceModel describes the data source data model.
@@ -120,18 +119,18 @@ func (d *PlatformDataSource) Configure(ctx context.Context, req datasource.Confi
 		return
 	}
 
-	client, ok := req.ProviderData.(*guku.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *http.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.client = client
+	d.client = data.Client
 }
 
 func (d *PlatformDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -144,9 +143,9 @@ func (d *PlatformDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
-	platform, err := d.client.GetPlatform(data.PlatformID.Value, data.PlatformVersion.Value)
+	platform, err := d.client.GetPlatform(ctx, data.PlatformID.Value, data.PlatformVersion.Value)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read platform, got error: %s", err))
+		resp.Diagnostics.Append(ClientErrorDiagnostic(ctx, "Unable to read platform", err))
 		return
 	}
 	if platform == nil {
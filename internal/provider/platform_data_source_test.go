@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPlatformDataSource_recorded(t *testing.T) {
+	recorder := newRecorder(t)
+
+	platformID := recorder.Value("platform_id", "GUKU_TEST_PLATFORM_ID")
+	platformVersion := recorder.Value("platform_version", "GUKU_TEST_PLATFORM_VERSION")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: recorder.ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: recorder.ProviderConfig() + fmt.Sprintf(`
+data "guku_platform" "test" {
+  platform_id      = %q
+  platform_version = %q
+}
+`, platformID, platformVersion),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.guku_platform.test", "platform_id", platformID),
+					resource.TestCheckResourceAttrSet("data.guku_platform.test", "name"),
+					resource.TestCheckResourceAttrSet("data.guku_platform.test", "min_api_version"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultConfigFile returns the location of the guku credentials file,
+// ~/.guku/credentials unless overridden with GUKU_CONFIG_FILE.
+func DefaultConfigFile() (string, error) {
+	if filename := os.Getenv("GUKU_CONFIG_FILE"); filename != "" {
+		return filename, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".guku", "credentials"), nil
+}
+
+// LoadProfile reads the named profile from an INI style credentials file:
+//
+//	[default]
+//	endpoint = https://...
+//	username = someone@example.com
+//	password = ...
+//
+// Lines starting with # or ; are ignored.
+func LoadProfile(filename string, name string) (map[string]string, error) {
+	if name == "" {
+		return nil, fmt.Errorf("profile name cannot be empty")
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var profile map[string]string
+	section := ""
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == name && profile == nil {
+				profile = map[string]string{}
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = value", filename, lineNumber)
+		}
+
+		if section == name {
+			profile[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if profile == nil {
+		return nil, fmt.Errorf("profile %q not found in %s", name, filename)
+	}
+	return profile, nil
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadProfile(t *testing.T) {
+	content := `
+# keys before the first section belong to no profile
+profile = ""
+
+[default]
+endpoint = https://guku.example.com/graphql
+username = someone@example.com
+; comments and blank lines are ignored
+
+password = p=ss = word
+
+[ ci ]
+api_token=token
+
+[default]
+region = eu-north-1
+`
+
+	testCases := map[string]struct {
+		name     string
+		content  string
+		expected map[string]string
+		err      string
+	}{
+		"default": {
+			name:    "default",
+			content: content,
+			expected: map[string]string{
+				"endpoint": "https://guku.example.com/graphql",
+				"username": "someone@example.com",
+				"password": "p=ss = word",
+				"region":   "eu-north-1",
+			},
+		},
+		"trimmed section": {
+			name:     "ci",
+			content:  content,
+			expected: map[string]string{"api_token": "token"},
+		},
+		"empty section": {
+			name:     "empty",
+			content:  "[empty]\n[other]\nkey = value\n",
+			expected: map[string]string{},
+		},
+		"missing": {
+			name:    "missing",
+			content: content,
+			err:     `profile "missing" not found`,
+		},
+		"empty name": {
+			name:    "",
+			content: content,
+			err:     "profile name cannot be empty",
+		},
+		"invalid line": {
+			name:    "default",
+			content: "[default]\nusername\n",
+			err:     ":2: expected key = value",
+		},
+	}
+
+	for name, testCase := range testCases {
+		filename := filepath.Join(t.TempDir(), "credentials")
+		if err := os.WriteFile(filename, []byte(testCase.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		profile, err := LoadProfile(filename, testCase.name)
+		if testCase.err != "" {
+			if err == nil || !strings.Contains(err.Error(), testCase.err) {
+				t.Errorf("%s: expected error containing %q, got %v", name, testCase.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(profile, testCase.expected) {
+			t.Errorf("%s: expected %v, got %v", name, testCase.expected, profile)
+		}
+	}
+}
+
+func TestLoadProfile_missingFile(t *testing.T) {
+	if _, err := LoadProfile(filepath.Join(t.TempDir(), "credentials"), "default"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
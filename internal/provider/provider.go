@@ -2,10 +2,15 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
-	"github.com/devopzilla/guku-client-go"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
@@ -16,6 +21,7 @@ import (
 // Ensure GukuProvider satisfies various provider interfaces.
 var _ provider.Provider = &GukuProvider{}
 var _ provider.ProviderWithMetadata = &GukuProvider{}
+var _ provider.ProviderWithConfigValidators = &GukuProvider{}
 
 const DEFAULT_ENDPOINT = "https://ztvgrcfy5bcvra2jmlfhsjw2ve.appsync-api.eu-north-1.amazonaws.com/graphql"
 
@@ -25,6 +31,23 @@ type GukuProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// client, when set, is used instead of a client configured from the
+	// provider attributes.
+	client GukuClient
+	// clock, when set, replaces the real clock used to wait for the guku
+	// API.
+	clock Clock
+	// wrapTransport, when set, wraps the transport of all guku API and
+	// authentication requests, for example to record them in tests.
+	wrapTransport func(http.RoundTripper) http.RoundTripper
+}
+
+// ProviderData is passed to resources and data sources once the provider is
+// configured.
+type ProviderData struct {
+	Client GukuClient
+	Waiter *Waiter
 }
 
 // GukuProviderModel describes the provider data model.
@@ -32,6 +55,53 @@ type GukuProviderModel struct {
 	Endpoint types.String `tfsdk:"endpoint"`
 	Username types.String `tfsdk:"username"`
 	Password types.String `tfsdk:"password"`
+
+	PasswordFile types.String `tfsdk:"password_file"`
+
+	ApiToken     types.String `tfsdk:"api_token"`
+	RefreshToken types.String `tfsdk:"refresh_token"`
+
+	Profile types.String `tfsdk:"profile"`
+
+	DisableSessionCache types.Bool `tfsdk:"disable_session_cache"`
+
+	CognitoUserPoolID types.String `tfsdk:"cognito_user_pool_id"`
+	CognitoClientID   types.String `tfsdk:"cognito_client_id"`
+	Region            types.String `tfsdk:"region"`
+
+	OIDCToken            types.String `tfsdk:"oidc_token"`
+	OIDCTokenFile        types.String `tfsdk:"oidc_token_file"`
+	OIDCTokenExchangeURL types.String `tfsdk:"oidc_token_exchange_url"`
+
+	Retry   *ProviderRetryModel   `tfsdk:"retry"`
+	Polling *ProviderPollingModel `tfsdk:"polling"`
+
+	MaxRequestsPerSecond  types.Float64 `tfsdk:"max_requests_per_second"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+
+	HTTPSProxy         types.String `tfsdk:"https_proxy"`
+	CABundle           types.String `tfsdk:"ca_bundle"`
+	CABundleFile       types.String `tfsdk:"ca_bundle_file"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+	RequestTimeout     types.String `tfsdk:"request_timeout"`
+
+	MemoryCatalog types.String `tfsdk:"memory_catalog"`
+}
+
+// ProviderRetryModel describes the retry block of the provider data model.
+type ProviderRetryModel struct {
+	MaxAttempts types.Int64   `tfsdk:"max_attempts"`
+	BaseDelay   types.String  `tfsdk:"base_delay"`
+	MaxDelay    types.String  `tfsdk:"max_delay"`
+	Jitter      types.Float64 `tfsdk:"jitter"`
+}
+
+// ProviderPollingModel describes the polling block of the provider data model.
+type ProviderPollingModel struct {
+	MaxAttempts types.Int64   `tfsdk:"max_attempts"`
+	Interval    types.String  `tfsdk:"interval"`
+	MaxInterval types.String  `tfsdk:"max_interval"`
+	Backoff     types.Float64 `tfsdk:"backoff"`
 }
 
 func (p *GukuProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -43,26 +113,224 @@ func (p *GukuProvider) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagno
 	return tfsdk.Schema{
 		Attributes: map[string]tfsdk.Attribute{
 			"endpoint": {
-				MarkdownDescription: "Example provider attribute",
-				Optional:            true,
-				Type:                types.StringType,
+				MarkdownDescription: "guku API endpoint. May also be set with the `GUKU_ENDPOINT` environment variable. " +
+					"Set to `memory://<path>` to use an in-memory backend instead of the guku API, for example with `terraform test`. " +
+					"The backend keeps its clusters and platform bindings in the file at `<path>`, so that they are kept between the Terraform commands of a run. " +
+					"No credentials are needed for the in-memory backend.",
+				Optional: true,
+				Type:     types.StringType,
 			},
 			"username": {
-				MarkdownDescription: "Example provider attribute",
-				Required:            true,
+				MarkdownDescription: "guku username. May also be set with the `GUKU_USERNAME` environment variable.",
+				Optional:            true,
 				Type:                types.StringType,
 			},
 			"password": {
-				MarkdownDescription: "Example provider attribute",
-				Required:            true,
+				MarkdownDescription: "guku password. May also be read from `password_file` or set with the `GUKU_PASSWORD` environment variable.",
+				Optional:            true,
 				Type:                types.StringType,
 				Sensitive:           true,
 			},
+			"password_file": {
+				MarkdownDescription: "Path to a file containing the guku password. Used when `password` is not set.",
+				Optional:            true,
+				Type:                types.StringType,
+			},
+			"api_token": {
+				MarkdownDescription: "guku API token, used instead of `username` and `password` to authenticate a service account. " +
+					"May also be set with the `GUKU_API_TOKEN` environment variable.",
+				Optional:  true,
+				Type:      types.StringType,
+				Sensitive: true,
+			},
+			"refresh_token": {
+				MarkdownDescription: "Cognito refresh token, used instead of `username` and `password`. The provider exchanges it for " +
+					"access tokens and exchanges it again whenever they expire. May also be set with the `GUKU_REFRESH_TOKEN` environment variable.",
+				Optional:  true,
+				Type:      types.StringType,
+				Sensitive: true,
+			},
+			"profile": {
+				MarkdownDescription: "Name of a profile in the guku credentials file (`~/.guku/credentials`, or `GUKU_CONFIG_FILE` when set) " +
+					"to read `endpoint`, `username`, `password`, `api_token` and `refresh_token` from. May also be set with the `GUKU_PROFILE` environment variable.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"disable_session_cache": {
+				MarkdownDescription: "Do not cache the guku session in `~/.guku/sessions` (or `GUKU_SESSION_CACHE_DIR` when set). " +
+					"When caching is disabled every provider run logs in again with `username` and `password`.",
+				Optional: true,
+				Type:     types.BoolType,
+			},
+			"cognito_user_pool_id": {
+				MarkdownDescription: "Cognito user pool id of a self-hosted guku installation. Required together with `cognito_client_id` and `region` " +
+					"when a custom `endpoint` is used. May also be set with the `GUKU_COGNITO_USER_POOL_ID` environment variable.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"cognito_client_id": {
+				MarkdownDescription: "Cognito app client id of a self-hosted guku installation. May also be set with the `GUKU_COGNITO_CLIENT_ID` environment variable.",
+				Optional:            true,
+				Type:                types.StringType,
+			},
+			"region": {
+				MarkdownDescription: "AWS region of the Cognito user pool of a self-hosted guku installation. May also be set with the `GUKU_REGION` environment variable.",
+				Optional:            true,
+				Type:                types.StringType,
+			},
+			"oidc_token": {
+				MarkdownDescription: "OIDC workload identity token, exchanged for guku credentials at `oidc_token_exchange_url`. " +
+					"May also be set with the `GUKU_OIDC_TOKEN` environment variable.",
+				Optional:  true,
+				Type:      types.StringType,
+				Sensitive: true,
+			},
+			"oidc_token_file": {
+				MarkdownDescription: "Path to a file containing an OIDC workload identity token. The file is read again whenever the token is exchanged, " +
+					"so rotated tokens are picked up. May also be set with the `GUKU_OIDC_TOKEN_FILE` environment variable.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"oidc_token_exchange_url": {
+				MarkdownDescription: "OAuth 2.0 token exchange endpoint that accepts OIDC tokens. Required when `oidc_token` or `oidc_token_file` is used. " +
+					"May also be set with the `GUKU_OIDC_TOKEN_EXCHANGE_URL` environment variable.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+			"max_requests_per_second": {
+				MarkdownDescription: "Maximum number of guku API requests per second, shared by all resources and data sources of this provider. " +
+					"Unlimited by default.",
+				Optional: true,
+				Type:     types.Float64Type,
+			},
+			"max_concurrent_requests": {
+				MarkdownDescription: "Maximum number of guku API requests in flight at the same time, shared by all resources and data sources of this provider. " +
+					"Unlimited by default.",
+				Optional: true,
+				Type:     types.Int64Type,
+			},
+			"https_proxy": {
+				MarkdownDescription: "Proxy used for the guku API and authentication requests. Defaults to the `HTTPS_PROXY` and `NO_PROXY` environment variables.",
+				Optional:            true,
+				Type:                types.StringType,
+			},
+			"ca_bundle": {
+				MarkdownDescription: "PEM encoded CA certificates trusted in addition to the system roots, for example those of a TLS intercepting proxy.",
+				Optional:            true,
+				Type:                types.StringType,
+			},
+			"ca_bundle_file": {
+				MarkdownDescription: "Path to a file containing PEM encoded CA certificates trusted in addition to the system roots.",
+				Optional:            true,
+				Type:                types.StringType,
+			},
+			"insecure_skip_verify": {
+				MarkdownDescription: "Skip TLS certificate verification. Only use this for testing, prefer `ca_bundle` or `ca_bundle_file`.",
+				Optional:            true,
+				Type:                types.BoolType,
+			},
+			"request_timeout": {
+				MarkdownDescription: "Timeout for each guku API and authentication request, such as `30s`. No timeout by default.",
+				Optional:            true,
+				Type:                types.StringType,
+			},
+			"memory_catalog": {
+				MarkdownDescription: "JSON list of the platforms served by the in-memory backend, in the format returned by the guku API " +
+					"(`platformID`, `platformVersion`, `name`, `configs`, ...), for example `file(\"platforms.json\")`. Only used with a `memory://` endpoint. May also be set with the `GUKU_MEMORY_CATALOG` environment variable.",
+				Optional: true,
+				Type:     types.StringType,
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"retry": {
+				MarkdownDescription: "Retry policy for throttled and transiently failing guku API requests. Requests are retried with exponential backoff. " +
+					"Mutations are only retried when the API rejected them without processing them.",
+				NestingMode: tfsdk.BlockNestingModeSingle,
+				Attributes: map[string]tfsdk.Attribute{
+					"max_attempts": {
+						MarkdownDescription: fmt.Sprintf("Total number of attempts per request, including the first one. Defaults to `%d`, set to `1` to disable retries.", DefaultRetryPolicy.MaxAttempts),
+						Optional:            true,
+						Type:                types.Int64Type,
+					},
+					"base_delay": {
+						MarkdownDescription: fmt.Sprintf("Delay before the first retry, doubled for every following retry. Defaults to `%s`.", DefaultRetryPolicy.BaseDelay),
+						Optional:            true,
+						Type:                types.StringType,
+					},
+					"max_delay": {
+						MarkdownDescription: fmt.Sprintf("Maximum delay between two attempts. Defaults to `%s`.", DefaultRetryPolicy.MaxDelay),
+						Optional:            true,
+						Type:                types.StringType,
+					},
+					"jitter": {
+						MarkdownDescription: fmt.Sprintf("Fraction of each delay that is randomized, between `0` and `1`. Defaults to `%g`.", DefaultRetryPolicy.Jitter),
+						Optional:            true,
+						Type:                types.Float64Type,
+					},
+				},
+			},
+			"polling": {
+				MarkdownDescription: "How resources poll the guku API while waiting for asynchronous operations, such as a platform binding leaving `Pending`.",
+				NestingMode:         tfsdk.BlockNestingModeSingle,
+				Attributes: map[string]tfsdk.Attribute{
+					"max_attempts": {
+						MarkdownDescription: fmt.Sprintf("Maximum number of polls before giving up, for resources whose `timeouts` do not set a limit. Defaults to `%d`.", DefaultPollPolicy.MaxAttempts),
+						Optional:            true,
+						Type:                types.Int64Type,
+					},
+					"interval": {
+						MarkdownDescription: fmt.Sprintf("Delay after the first poll. Defaults to `%s`.", DefaultPollPolicy.Interval),
+						Optional:            true,
+						Type:                types.StringType,
+					},
+					"max_interval": {
+						MarkdownDescription: fmt.Sprintf("Maximum delay between two polls. Defaults to `%s`.", DefaultPollPolicy.MaxInterval),
+						Optional:            true,
+						Type:                types.StringType,
+					},
+					"backoff": {
+						MarkdownDescription: fmt.Sprintf("Factor the delay is multiplied by after every poll, at least `1`. Defaults to `%g`, which polls at a fixed interval.", DefaultPollPolicy.Backoff),
+						Optional:            true,
+						Type:                types.Float64Type,
+					},
+				},
+			},
 		},
 	}, nil
 }
 
+func (p *GukuProvider) ConfigValidators(ctx context.Context) []provider.ConfigValidator {
+	return []provider.ConfigValidator{
+		&conflictingAttributesValidator{
+			attribute: "api_token",
+			conflicts: []string{"username", "password", "password_file", "refresh_token", "oidc_token", "oidc_token_file"},
+		},
+		&conflictingAttributesValidator{
+			attribute: "refresh_token",
+			conflicts: []string{"username", "password", "password_file", "oidc_token", "oidc_token_file"},
+		},
+		&conflictingAttributesValidator{
+			attribute: "oidc_token",
+			conflicts: []string{"username", "password", "password_file", "oidc_token_file"},
+		},
+		&conflictingAttributesValidator{
+			attribute: "oidc_token_file",
+			conflicts: []string{"username", "password", "password_file"},
+		},
+		&conflictingAttributesValidator{
+			attribute: "ca_bundle",
+			conflicts: []string{"ca_bundle_file"},
+		},
+		&cognitoConfigValidator{},
+	}
+}
+
 func (p *GukuProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	if p.client != nil {
+		p.setProviderData(resp, p.client, DefaultPollPolicy, realClock{})
+		return
+	}
+
 	var data GukuProviderModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -71,31 +339,87 @@ func (p *GukuProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	}
 
-	if data.Endpoint.IsNull() {
-		data.Endpoint = types.String{Value: DEFAULT_ENDPOINT}
-		// resp.Diagnostics.AddError(
-		// 	"Unable to find Endpoint",
-		// 	"Endpoint cannot be empty",
-		// )
-		// return
+	resp.Diagnostics.Append(resolveProviderConfig(&data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if data.Username.IsNull() {
-		resp.Diagnostics.AddError(
-			"Unable to find Username",
-			"Username cannot be empty",
+
+	retryPolicy, diags := retryPolicy(data.Retry)
+	resp.Diagnostics.Append(diags...)
+
+	pollPolicy, diags := pollPolicy(data.Polling)
+	resp.Diagnostics.Append(diags...)
+
+	if data.MaxRequestsPerSecond.Value < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_requests_per_second"),
+			"Invalid Request Limit",
+			fmt.Sprintf("max_requests_per_second cannot be negative, got: %g", data.MaxRequestsPerSecond.Value),
 		)
+	}
+	if data.MaxConcurrentRequests.Value < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_concurrent_requests"),
+			"Invalid Request Limit",
+			fmt.Sprintf("max_concurrent_requests cannot be negative, got: %d", data.MaxConcurrentRequests.Value),
+		)
+	}
+
+	if isMemoryEndpoint(data.Endpoint.Value) {
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		tflog.Info(ctx, "Using in-memory guku backend")
+		client, err := NewMemoryClient(data.Endpoint.Value, data.MemoryCatalog.Value)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to create in-memory client",
+				"Unable to create in-memory guku client:\n\n"+err.Error(),
+			)
+			return
+		}
+
+		// nothing to wait for, objects are ready as soon as they are created
+		p.setProviderData(resp, client, pollPolicy, instantClock{})
+		return
+	}
+
+	transportOptions, diags := transportOptions(data)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	if data.Password.IsNull() {
+
+	httpClient, err := NewHTTPClient(transportOptions)
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Unable to find Password",
-			"Password cannot be empty",
+			"Unable to create HTTP client",
+			"Unable to create HTTP client for the guku API:\n\n"+err.Error(),
 		)
 		return
 	}
+	if p.wrapTransport != nil {
+		httpClient.Transport = p.wrapTransport(httpClient.Transport)
+	}
 
-	client, err := guku.NewClient(context.TODO(), data.Endpoint.Value, data.Username.Value, data.Password.Value)
-	tflog.Info(ctx, data.Username.Value)
+	var credentials Credentials
+	switch {
+	case !data.ApiToken.IsNull():
+		tflog.Info(ctx, "Authenticating with guku API token")
+		credentials = &APITokenCredentials{Token: data.ApiToken.Value}
+	case !data.RefreshToken.IsNull():
+		tflog.Info(ctx, "Authenticating with guku refresh token")
+		credentials, err = CognitoRefreshTokenSession(ctx, cognitoConfig(data), httpClient, data.RefreshToken.Value)
+	case !data.OIDCToken.IsNull() || !data.OIDCTokenFile.IsNull():
+		tflog.Info(ctx, "Authenticating with OIDC token exchange")
+		credentials, err = NewOIDCCredentials(ctx, httpClient, data.OIDCTokenExchangeURL.Value, data.OIDCToken.Value, data.OIDCTokenFile.Value)
+	default:
+		tflog.Info(ctx, data.Username.Value)
+		credentials, err = cognitoSession(ctx, data, httpClient)
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to create client",
@@ -104,8 +428,272 @@ func (p *GukuProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	}
 
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	client := NewClient(data.Endpoint.Value, credentials, ClientOptions{
+		HTTPClient:            httpClient,
+		RetryPolicy:           retryPolicy,
+		MaxRequestsPerSecond:  data.MaxRequestsPerSecond.Value,
+		MaxConcurrentRequests: int(data.MaxConcurrentRequests.Value),
+	})
+
+	p.setProviderData(resp, client, pollPolicy, realClock{})
+}
+
+func (p *GukuProvider) setProviderData(resp *provider.ConfigureResponse, client GukuClient, pollPolicy PollPolicy, clock Clock) {
+	if p.clock != nil {
+		clock = p.clock
+	}
+
+	data := &ProviderData{
+		Client: client,
+		Waiter: &Waiter{
+			Clock:  clock,
+			Policy: pollPolicy,
+		},
+	}
+	resp.DataSourceData = data
+	resp.ResourceData = data
+}
+
+// cognitoSession returns a Cognito session for the configured user. Unless
+// disabled, a session cached by an earlier run is reused and refreshed as
+// needed, and new sessions are written back to the cache.
+func cognitoSession(ctx context.Context, data GukuProviderModel, httpClient *http.Client) (*CognitoCredentials, error) {
+	endpoint, cognito, username := data.Endpoint.Value, cognitoConfig(data), data.Username.Value
+
+	var cache *SessionCache
+	if !data.DisableSessionCache.Value {
+		var err error
+		cache, err = DefaultSessionCache()
+		if err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Unable to locate guku session cache, got error: %s", err))
+		}
+	}
+
+	store := func(credentials *CognitoCredentials) {
+		if err := cache.Store(endpoint, cognito, username, credentials); err != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Unable to cache guku session, got error: %s", err))
+		}
+	}
+
+	if cache != nil {
+		credentials, err := cache.Load(endpoint, cognito, username)
+		if err == nil {
+			credentials.HTTPClient = httpClient
+			credentials.OnRefresh = store
+			err = credentials.Refresh(ctx)
+		}
+		if err == nil {
+			tflog.Debug(ctx, "Using cached guku session")
+			return credentials, nil
+		}
+		tflog.Debug(ctx, fmt.Sprintf("No usable cached guku session, logging in: %s", err))
+	}
+
+	credentials, err := CognitoLogin(ctx, cognito, httpClient, data.Username.Value, data.Password.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		store(credentials)
+		credentials.OnRefresh = store
+	}
+	return credentials, nil
+}
+
+// cognitoConfig returns the configured Cognito user pool, falling back to the
+// pool of the hosted guku service.
+func cognitoConfig(data GukuProviderModel) CognitoConfig {
+	if data.CognitoUserPoolID.IsNull() {
+		return DefaultCognitoConfig
+	}
+	return CognitoConfig{
+		Region:     data.Region.Value,
+		UserPoolID: data.CognitoUserPoolID.Value,
+		ClientID:   data.CognitoClientID.Value,
+	}
+}
+
+// transportOptions returns the configured HTTP transport options.
+func transportOptions(data GukuProviderModel) (TransportOptions, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	options := TransportOptions{
+		ProxyURL:           data.HTTPSProxy.Value,
+		InsecureSkipVerify: data.InsecureSkipVerify.Value,
+	}
+
+	switch {
+	case !data.CABundle.IsNull():
+		options.CABundle = []byte(data.CABundle.Value)
+	case !data.CABundleFile.IsNull():
+		content, err := os.ReadFile(data.CABundleFile.Value)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("ca_bundle_file"),
+				"Unable to read ca_bundle_file",
+				fmt.Sprintf("Unable to read CA bundle from %s:\n\n%s", data.CABundleFile.Value, err),
+			)
+		}
+		options.CABundle = content
+	}
+
+	if !data.RequestTimeout.IsNull() {
+		timeout, err := time.ParseDuration(data.RequestTimeout.Value)
+		if err != nil || timeout < 0 {
+			diags.AddAttributeError(
+				path.Root("request_timeout"),
+				"Invalid Request Timeout",
+				fmt.Sprintf("request_timeout must be a positive duration such as \"30s\" or \"2m\", got: %s", data.RequestTimeout.Value),
+			)
+		}
+		options.Timeout = timeout
+	}
+
+	if options.InsecureSkipVerify {
+		diags.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"TLS Verification Disabled",
+			"insecure_skip_verify is set, so the certificates of the guku API and authentication endpoints are not verified. "+
+				"This makes the connection vulnerable to interception, consider setting ca_bundle or ca_bundle_file instead.",
+		)
+	}
+
+	return options, diags
+}
+
+// retryPolicy returns the configured retry policy, using the defaults for
+// anything that is not set.
+func retryPolicy(data *ProviderRetryModel) (RetryPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	policy := DefaultRetryPolicy
+	if data == nil {
+		return policy, diags
+	}
+
+	if !data.MaxAttempts.IsNull() && !data.MaxAttempts.IsUnknown() {
+		if data.MaxAttempts.Value < 1 {
+			diags.AddAttributeError(
+				path.Root("retry").AtName("max_attempts"),
+				"Invalid Retry Max Attempts",
+				fmt.Sprintf("max_attempts must be at least 1, got: %d", data.MaxAttempts.Value),
+			)
+		}
+		policy.MaxAttempts = int(data.MaxAttempts.Value)
+	}
+
+	for _, delay := range []struct {
+		name  string
+		val   types.String
+		delay *time.Duration
+	}{
+		{"base_delay", data.BaseDelay, &policy.BaseDelay},
+		{"max_delay", data.MaxDelay, &policy.MaxDelay},
+	} {
+		if delay.val.IsNull() || delay.val.IsUnknown() {
+			continue
+		}
+
+		parsed, err := time.ParseDuration(delay.val.Value)
+		if err != nil || parsed < 0 {
+			diags.AddAttributeError(
+				path.Root("retry").AtName(delay.name),
+				"Invalid Retry Delay",
+				fmt.Sprintf("%s must be a positive duration such as \"500ms\" or \"2s\", got: %s", delay.name, delay.val.Value),
+			)
+			continue
+		}
+		*delay.delay = parsed
+	}
+
+	if !data.Jitter.IsNull() && !data.Jitter.IsUnknown() {
+		if data.Jitter.Value < 0 || data.Jitter.Value > 1 {
+			diags.AddAttributeError(
+				path.Root("retry").AtName("jitter"),
+				"Invalid Retry Jitter",
+				fmt.Sprintf("jitter must be between 0 and 1, got: %g", data.Jitter.Value),
+			)
+		}
+		policy.Jitter = data.Jitter.Value
+	}
+
+	if policy.BaseDelay > policy.MaxDelay {
+		diags.AddAttributeError(
+			path.Root("retry").AtName("base_delay"),
+			"Invalid Retry Delay",
+			fmt.Sprintf("base_delay (%s) cannot be longer than max_delay (%s)", policy.BaseDelay, policy.MaxDelay),
+		)
+	}
+
+	return policy, diags
+}
+
+// pollPolicy returns the configured poll policy, using the defaults for
+// anything that is not set.
+func pollPolicy(data *ProviderPollingModel) (PollPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	policy := DefaultPollPolicy
+	if data == nil {
+		return policy, diags
+	}
+
+	if !data.MaxAttempts.IsNull() && !data.MaxAttempts.IsUnknown() {
+		if data.MaxAttempts.Value < 1 {
+			diags.AddAttributeError(
+				path.Root("polling").AtName("max_attempts"),
+				"Invalid Polling Max Attempts",
+				fmt.Sprintf("max_attempts must be at least 1, got: %d", data.MaxAttempts.Value),
+			)
+		}
+		policy.MaxAttempts = int(data.MaxAttempts.Value)
+	}
+
+	for _, interval := range []struct {
+		name     string
+		val      types.String
+		interval *time.Duration
+	}{
+		{"interval", data.Interval, &policy.Interval},
+		{"max_interval", data.MaxInterval, &policy.MaxInterval},
+	} {
+		if interval.val.IsNull() || interval.val.IsUnknown() {
+			continue
+		}
+
+		parsed, err := time.ParseDuration(interval.val.Value)
+		if err != nil || parsed < 0 {
+			diags.AddAttributeError(
+				path.Root("polling").AtName(interval.name),
+				"Invalid Polling Interval",
+				fmt.Sprintf("%s must be a positive duration such as \"10s\" or \"1m\", got: %s", interval.name, interval.val.Value),
+			)
+			continue
+		}
+		*interval.interval = parsed
+	}
+
+	if !data.Backoff.IsNull() && !data.Backoff.IsUnknown() {
+		if data.Backoff.Value < 1 {
+			diags.AddAttributeError(
+				path.Root("polling").AtName("backoff"),
+				"Invalid Polling Backoff",
+				fmt.Sprintf("backoff must be at least 1, got: %g", data.Backoff.Value),
+			)
+		}
+		policy.Backoff = data.Backoff.Value
+	}
+
+	if policy.Interval > policy.MaxInterval {
+		diags.AddAttributeError(
+			path.Root("polling").AtName("interval"),
+			"Invalid Polling Interval",
+			fmt.Sprintf("interval (%s) cannot be longer than max_interval (%s)", policy.Interval, policy.MaxInterval),
+		)
+	}
+
+	return policy, diags
 }
 
 func (p *GukuProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -128,3 +716,180 @@ func New(version string) func() provider.Provider {
 		}
 	}
 }
+
+// NewWithClient returns a provider factory whose resources and data sources
+// use client instead of connecting to the guku API.
+func NewWithClient(version string, client GukuClient) func() provider.Provider {
+	return func() provider.Provider {
+		return &GukuProvider{
+			version: version,
+			client:  client,
+		}
+	}
+}
+
+// resolveProviderConfig fills in any provider attributes that were not set in
+// the configuration. Explicit configuration always wins, followed by
+// password_file for the password, then the selected profile, and finally the
+// GUKU_* environment variables. An API token from a profile or GUKU_API_TOKEN
+// is only used when no username or password was found before it.
+func resolveProviderConfig(data *GukuProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, attr := range []struct {
+		name string
+		val  types.String
+	}{
+		{"endpoint", data.Endpoint},
+		{"username", data.Username},
+		{"password", data.Password},
+		{"password_file", data.PasswordFile},
+		{"api_token", data.ApiToken},
+		{"refresh_token", data.RefreshToken},
+		{"profile", data.Profile},
+		{"cognito_user_pool_id", data.CognitoUserPoolID},
+		{"cognito_client_id", data.CognitoClientID},
+		{"region", data.Region},
+		{"oidc_token", data.OIDCToken},
+		{"oidc_token_file", data.OIDCTokenFile},
+		{"oidc_token_exchange_url", data.OIDCTokenExchangeURL},
+		{"https_proxy", data.HTTPSProxy},
+		{"ca_bundle", data.CABundle},
+		{"ca_bundle_file", data.CABundleFile},
+		{"request_timeout", data.RequestTimeout},
+		{"memory_catalog", data.MemoryCatalog},
+	} {
+		if attr.val.IsUnknown() {
+			diags.AddAttributeError(
+				path.Root(attr.name),
+				"Unknown guku Provider Attribute",
+				fmt.Sprintf("The provider cannot create the guku API client as there is an unknown configuration value for %s. "+
+					"Either target apply the source of the value first, set the value statically in the configuration, or leave it unset to use its fallback.", attr.name),
+			)
+		}
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	data.Profile = stringFallback(data.Profile, os.Getenv("GUKU_PROFILE"))
+
+	profile := map[string]string{}
+	if !data.Profile.IsNull() {
+		filename, err := DefaultConfigFile()
+		if err == nil {
+			profile, err = LoadProfile(filename, data.Profile.Value)
+		}
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("profile"),
+				"Unable to load guku profile",
+				fmt.Sprintf("Unable to load guku profile %s, set GUKU_CONFIG_FILE to use a different credentials file:\n\n%s", data.Profile.Value, err),
+			)
+			return diags
+		}
+	}
+
+	data.Endpoint = stringFallback(data.Endpoint, profile["endpoint"], os.Getenv("GUKU_ENDPOINT"), DEFAULT_ENDPOINT)
+	if isMemoryEndpoint(data.Endpoint.Value) {
+		// every Terraform command starts a new provider, so the objects of
+		// the in-memory backend only survive in a file
+		if data.Endpoint.Value == MemoryEndpointPrefix {
+			diags.AddAttributeError(
+				path.Root("endpoint"),
+				"Missing In-Memory Backend File",
+				"The in-memory backend keeps its clusters and platform bindings in a file between Terraform commands. "+
+					"Set the endpoint to memory://<path>, for example memory://guku-state.json.",
+			)
+		}
+		// the in-memory backend needs no credentials
+		data.MemoryCatalog = stringFallback(data.MemoryCatalog, os.Getenv("GUKU_MEMORY_CATALOG"))
+		return diags
+	}
+
+	data.CognitoUserPoolID = stringFallback(data.CognitoUserPoolID, profile["cognito_user_pool_id"], os.Getenv("GUKU_COGNITO_USER_POOL_ID"))
+	data.CognitoClientID = stringFallback(data.CognitoClientID, profile["cognito_client_id"], os.Getenv("GUKU_COGNITO_CLIENT_ID"))
+	data.Region = stringFallback(data.Region, profile["region"], os.Getenv("GUKU_REGION"))
+
+	credentialsConfigured := false
+	for _, val := range []types.String{data.Username, data.Password, data.PasswordFile, data.ApiToken, data.RefreshToken, data.OIDCToken, data.OIDCTokenFile} {
+		credentialsConfigured = credentialsConfigured || !val.IsNull()
+	}
+
+	if !credentialsConfigured {
+		switch {
+		case profile["api_token"] != "":
+			data.ApiToken = types.String{Value: profile["api_token"]}
+		case profile["refresh_token"] != "":
+			data.RefreshToken = types.String{Value: profile["refresh_token"]}
+		case profile["username"] != "" || profile["password"] != "":
+			// the profile selects username and password authentication
+		case os.Getenv("GUKU_API_TOKEN") != "":
+			data.ApiToken = types.String{Value: os.Getenv("GUKU_API_TOKEN")}
+		case os.Getenv("GUKU_REFRESH_TOKEN") != "":
+			data.RefreshToken = types.String{Value: os.Getenv("GUKU_REFRESH_TOKEN")}
+		case os.Getenv("GUKU_OIDC_TOKEN") != "":
+			data.OIDCToken = types.String{Value: os.Getenv("GUKU_OIDC_TOKEN")}
+		case os.Getenv("GUKU_OIDC_TOKEN_FILE") != "":
+			data.OIDCTokenFile = types.String{Value: os.Getenv("GUKU_OIDC_TOKEN_FILE")}
+		}
+	}
+
+	if !data.OIDCToken.IsNull() || !data.OIDCTokenFile.IsNull() {
+		data.OIDCTokenExchangeURL = stringFallback(data.OIDCTokenExchangeURL, profile["oidc_token_exchange_url"], os.Getenv("GUKU_OIDC_TOKEN_EXCHANGE_URL"))
+		if data.OIDCTokenExchangeURL.IsNull() {
+			diags.AddAttributeError(
+				path.Root("oidc_token_exchange_url"),
+				"Missing guku OIDC Token Exchange URL",
+				"An OIDC token was set but no token exchange endpoint was found. Set the oidc_token_exchange_url attribute in the provider configuration, "+
+					"an oidc_token_exchange_url in the selected profile, or the GUKU_OIDC_TOKEN_EXCHANGE_URL environment variable.",
+			)
+		}
+		return diags
+	}
+
+	// only Cognito based authentication needs to know the user pool
+	if data.ApiToken.IsNull() {
+		diags.Append(validateCognitoConfig(*data)...)
+	}
+
+	// tokens replace username and password authentication
+	if !data.ApiToken.IsNull() || !data.RefreshToken.IsNull() {
+		return diags
+	}
+
+	if data.Password.IsNull() && !data.PasswordFile.IsNull() {
+		content, err := os.ReadFile(data.PasswordFile.Value)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("password_file"),
+				"Unable to read password_file",
+				fmt.Sprintf("Unable to read guku password from %s:\n\n%s", data.PasswordFile.Value, err),
+			)
+			return diags
+		}
+		data.Password = types.String{Value: strings.TrimRight(string(content), "\r\n")}
+	}
+
+	data.Username = stringFallback(data.Username, profile["username"], os.Getenv("GUKU_USERNAME"))
+	data.Password = stringFallback(data.Password, profile["password"], os.Getenv("GUKU_PASSWORD"))
+
+	if data.Username.IsNull() || data.Username.Value == "" {
+		diags.AddAttributeError(
+			path.Root("username"),
+			"Missing guku Username",
+			"No guku username was found. Set the username attribute in the provider configuration, "+
+				"a username in the selected profile, or the GUKU_USERNAME environment variable, or authenticate with api_token instead.",
+		)
+	}
+	if data.Password.IsNull() || data.Password.Value == "" {
+		diags.AddAttributeError(
+			path.Root("password"),
+			"Missing guku Password",
+			"No guku password was found. Set the password attribute or the password_file attribute in the provider configuration, "+
+				"a password in the selected profile, or the GUKU_PASSWORD environment variable.",
+		)
+	}
+
+	return diags
+}
@@ -1,10 +1,20 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -12,7 +22,23 @@ import (
 // CLI command executed to create a provider server to which the CLI can
 // reattach.
 var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"scaffolding": providerserver.NewProtocol6WithError(New("test")()),
+	"guku": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testAccProtoV6ProviderFactoriesWithClock returns provider factories whose
+// provider waits on clock instead of in real time.
+func testAccProtoV6ProviderFactoriesWithClock(clock Clock) map[string]func() (tfprotov6.ProviderServer, error) {
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"guku": providerserver.NewProtocol6WithError(&GukuProvider{version: "test", clock: clock}),
+	}
+}
+
+// testAccProtoV6ProviderFactoriesWithTransport returns provider factories
+// whose provider wraps the transport of its HTTP client with wrap.
+func testAccProtoV6ProviderFactoriesWithTransport(wrap func(http.RoundTripper) http.RoundTripper) map[string]func() (tfprotov6.ProviderServer, error) {
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"guku": providerserver.NewProtocol6WithError(&GukuProvider{version: "test", wrapTransport: wrap}),
+	}
 }
 
 func testAccPreCheck(t *testing.T) {
@@ -20,3 +46,244 @@ func testAccPreCheck(t *testing.T) {
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+func TestProviderConfigure_injectedClient(t *testing.T) {
+	client := &Client{}
+
+	resp := &provider.ConfigureResponse{}
+	NewWithClient("test", client)().Configure(context.Background(), provider.ConfigureRequest{}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	data, ok := resp.ResourceData.(*ProviderData)
+	if !ok || data.Client != client || resp.DataSourceData != data {
+		t.Errorf("expected injected client to be passed to resources and data sources")
+	}
+}
+
+func TestAccProvider_passwordAuthentication(t *testing.T) {
+	fake := newFakeGuku(t)
+	var clusterID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithTransport(fake.Cognito().Wrap),
+		CheckDestroy:             testAccCheckClusterDestroyed(fake),
+		Steps: []resource.TestStep{
+			{
+				Config: fake.PasswordProviderConfig() + `
+resource "guku_cluster" "test" {
+  name        = "test"
+  token       = "service-account-token"
+  api_version = "1.24"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckClusterExists(fake, "guku_cluster.test", &clusterID),
+					func(*terraform.State) error {
+						if fake.Cognito().Requests("USER_SRP_AUTH") == 0 {
+							return fmt.Errorf("expected the provider to log in with username and password")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// providerEnv are the environment variables read when configuring the
+// provider.
+var providerEnv = []string{
+	"GUKU_CONFIG_FILE", "GUKU_PROFILE", "GUKU_ENDPOINT", "GUKU_USERNAME", "GUKU_PASSWORD", "GUKU_API_TOKEN", "GUKU_REFRESH_TOKEN",
+	"GUKU_OIDC_TOKEN", "GUKU_OIDC_TOKEN_FILE", "GUKU_OIDC_TOKEN_EXCHANGE_URL", "GUKU_COGNITO_USER_POOL_ID", "GUKU_COGNITO_CLIENT_ID",
+	"GUKU_REGION", "GUKU_MEMORY_CATALOG",
+}
+
+// testProviderModel returns a provider model with the string attributes in
+// attrs set and all others null, as if read from a provider block.
+func testProviderModel(attrs map[string]string) GukuProviderModel {
+	var data GukuProviderModel
+
+	model := reflect.ValueOf(&data).Elem()
+	for i := 0; i < model.NumField(); i++ {
+		if model.Field(i).Type() != reflect.TypeOf(types.String{}) {
+			continue
+		}
+		val, ok := attrs[model.Type().Field(i).Tag.Get("tfsdk")]
+		model.Field(i).Set(reflect.ValueOf(types.String{Value: val, Null: !ok}))
+	}
+	return data
+}
+
+func TestResolveProviderConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("file-password\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(configFile, []byte(`
+[ci]
+endpoint = https://profile.example.com/graphql
+cognito_user_pool_id = eu-north-1_profile
+cognito_client_id = profile-client
+region = eu-north-1
+username = profile-user
+password = profile-password
+
+[service]
+api_token = profile-token
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		attrs map[string]string
+		env   map[string]string
+		// expected are the resolved attributes, compared when set
+		expected map[string]string
+		errors   []string
+	}{
+		"config": {
+			attrs:    map[string]string{"username": "config-user", "password": "config-password"},
+			env:      map[string]string{"GUKU_USERNAME": "env-user", "GUKU_PASSWORD": "env-password"},
+			expected: map[string]string{"endpoint": DEFAULT_ENDPOINT, "username": "config-user", "password": "config-password"},
+		},
+		"environment": {
+			env:      map[string]string{"GUKU_ENDPOINT": DEFAULT_ENDPOINT, "GUKU_USERNAME": "env-user", "GUKU_PASSWORD": "env-password"},
+			expected: map[string]string{"username": "env-user", "password": "env-password"},
+		},
+		"password_file": {
+			attrs:    map[string]string{"username": "config-user", "password_file": passwordFile},
+			env:      map[string]string{"GUKU_PASSWORD": "env-password"},
+			expected: map[string]string{"username": "config-user", "password": "file-password"},
+		},
+		"password over password_file": {
+			attrs:    map[string]string{"username": "config-user", "password": "config-password", "password_file": filepath.Join(dir, "missing")},
+			expected: map[string]string{"password": "config-password"},
+		},
+		"profile": {
+			attrs: map[string]string{"profile": "ci"},
+			env:   map[string]string{"GUKU_ENDPOINT": "https://env.example.com/graphql", "GUKU_USERNAME": "env-user"},
+			expected: map[string]string{
+				"endpoint":             "https://profile.example.com/graphql",
+				"cognito_user_pool_id": "eu-north-1_profile",
+				"username":             "profile-user",
+				"password":             "profile-password",
+			},
+		},
+		"profile from environment": {
+			env:      map[string]string{"GUKU_PROFILE": "service", "GUKU_USERNAME": "env-user", "GUKU_PASSWORD": "env-password"},
+			expected: map[string]string{"api_token": "profile-token", "username": ""},
+		},
+		"config over profile": {
+			attrs:    map[string]string{"profile": "ci", "password": "config-password"},
+			expected: map[string]string{"username": "profile-user", "password": "config-password"},
+		},
+		"api_token from environment": {
+			env:      map[string]string{"GUKU_API_TOKEN": "env-token"},
+			expected: map[string]string{"api_token": "env-token", "username": ""},
+		},
+		"missing username": {
+			env:    map[string]string{"GUKU_PASSWORD": "env-password"},
+			errors: []string{"Missing guku Username"},
+		},
+		"missing password": {
+			attrs:  map[string]string{"username": "config-user"},
+			errors: []string{"Missing guku Password"},
+		},
+		"missing credentials": {
+			errors: []string{"Missing guku Username", "Missing guku Password"},
+		},
+		"unreadable password_file": {
+			attrs:  map[string]string{"username": "config-user", "password_file": filepath.Join(dir, "missing")},
+			errors: []string{"Unable to read password_file"},
+		},
+		"custom endpoint": {
+			attrs: map[string]string{
+				"endpoint":             "https://guku.example.com/graphql",
+				"cognito_user_pool_id": "eu-west-1_custom",
+				"cognito_client_id":    "custom-client",
+				"region":               "eu-west-1",
+				"username":             "config-user",
+				"password":             "config-password",
+			},
+			expected: map[string]string{"endpoint": "https://guku.example.com/graphql", "cognito_user_pool_id": "eu-west-1_custom"},
+		},
+		"custom endpoint with user pool from environment": {
+			attrs: map[string]string{"endpoint": "https://guku.example.com/graphql", "username": "config-user", "password": "config-password"},
+			env: map[string]string{
+				"GUKU_COGNITO_USER_POOL_ID": "eu-west-1_env",
+				"GUKU_COGNITO_CLIENT_ID":    "env-client",
+				"GUKU_REGION":               "eu-west-1",
+			},
+			expected: map[string]string{"cognito_user_pool_id": "eu-west-1_env"},
+		},
+		"custom endpoint without user pool": {
+			attrs:  map[string]string{"endpoint": "https://guku.example.com/graphql", "username": "config-user", "password": "config-password"},
+			errors: []string{"Missing guku Cognito Configuration"},
+		},
+		"custom endpoint with api_token": {
+			attrs:    map[string]string{"endpoint": "https://guku.example.com/graphql", "api_token": "config-token"},
+			expected: map[string]string{"api_token": "config-token"},
+		},
+		"incomplete user pool": {
+			attrs:  map[string]string{"cognito_user_pool_id": "eu-west-1_custom", "username": "config-user", "password": "config-password"},
+			errors: []string{"Incomplete guku Cognito Configuration"},
+		},
+		"memory endpoint": {
+			attrs:    map[string]string{"endpoint": "memory://guku-state.json"},
+			expected: map[string]string{"endpoint": "memory://guku-state.json", "username": ""},
+		},
+		"memory endpoint without file": {
+			attrs:  map[string]string{"endpoint": "memory://"},
+			errors: []string{"Missing In-Memory Backend File"},
+		},
+		"unknown profile": {
+			attrs:  map[string]string{"profile": "missing"},
+			errors: []string{"Unable to load guku profile"},
+		},
+		"empty profile": {
+			attrs:  map[string]string{"profile": ""},
+			errors: []string{"Unable to load guku profile"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			for _, env := range providerEnv {
+				t.Setenv(env, testCase.env[env])
+			}
+			t.Setenv("GUKU_CONFIG_FILE", configFile)
+
+			data := testProviderModel(testCase.attrs)
+			diags := resolveProviderConfig(&data)
+
+			var errors []string
+			for _, diagnostic := range diags.Errors() {
+				errors = append(errors, diagnostic.Summary())
+			}
+			if !reflect.DeepEqual(errors, testCase.errors) {
+				t.Fatalf("expected errors %v, got %v", testCase.errors, diags)
+			}
+
+			resolved := map[string]types.String{
+				"endpoint":             data.Endpoint,
+				"cognito_user_pool_id": data.CognitoUserPoolID,
+				"username":             data.Username,
+				"password":             data.Password,
+				"api_token":            data.ApiToken,
+			}
+			for attr, expected := range testCase.expected {
+				if resolved[attr].Value != expected {
+					t.Errorf("expected %s %q, got %q", attr, expected, resolved[attr].Value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ provider.ConfigValidator = &conflictingAttributesValidator{}
+var _ provider.ConfigValidator = &cognitoConfigValidator{}
+
+var cognitoConfigAttributes = []string{"cognito_user_pool_id", "cognito_client_id", "region"}
+
+// conflictingAttributesValidator rejects provider configurations that set
+// attribute together with any of conflicts.
+type conflictingAttributesValidator struct {
+	attribute string
+	conflicts []string
+}
+
+func (v *conflictingAttributesValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("%s cannot be set together with %s", v.attribute, strings.Join(v.conflicts, ", "))
+}
+
+func (v *conflictingAttributesValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("`%s` cannot be set together with `%s`", v.attribute, strings.Join(v.conflicts, "`, `"))
+}
+
+func (v *conflictingAttributesValidator) ValidateProvider(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var val types.String
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(v.attribute), &val)...)
+
+	if resp.Diagnostics.HasError() || val.IsNull() || val.IsUnknown() {
+		return
+	}
+
+	for _, conflict := range v.conflicts {
+		var conflictVal types.String
+
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(conflict), &conflictVal)...)
+
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if !conflictVal.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(v.attribute),
+				"Conflicting guku Provider Attributes",
+				fmt.Sprintf("%s cannot be set together with %s.", v.attribute, conflict),
+			)
+		}
+	}
+}
+
+// cognitoConfigValidator requires the Cognito user pool attributes to be set
+// together.
+type cognitoConfigValidator struct{}
+
+func (v *cognitoConfigValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("%s must be set together", strings.Join(cognitoConfigAttributes, ", "))
+}
+
+func (v *cognitoConfigValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("`%s` must be set together", strings.Join(cognitoConfigAttributes, "`, `"))
+}
+
+func (v *cognitoConfigValidator) ValidateProvider(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var data GukuProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the user pool may still be provided by a profile or the environment,
+	// so a custom endpoint is only checked once the provider is configured
+	data.Endpoint = types.String{Value: DEFAULT_ENDPOINT}
+
+	for _, val := range []types.String{data.CognitoUserPoolID, data.CognitoClientID, data.Region} {
+		if val.IsUnknown() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(validateCognitoConfig(data)...)
+}
+
+// validateCognitoConfig checks that the Cognito user pool attributes are
+// either all set or all unset, and that they are set for a custom endpoint.
+func validateCognitoConfig(data GukuProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	set := 0
+	for _, val := range []types.String{data.CognitoUserPoolID, data.CognitoClientID, data.Region} {
+		if !val.IsNull() {
+			set++
+		}
+	}
+
+	switch {
+	case set > 0 && set < len(cognitoConfigAttributes):
+		diags.AddError(
+			"Incomplete guku Cognito Configuration",
+			fmt.Sprintf("%s must be set together.", strings.Join(cognitoConfigAttributes, ", ")),
+		)
+	case set == 0 && data.Endpoint.Value != DEFAULT_ENDPOINT:
+		diags.AddAttributeError(
+			path.Root("endpoint"),
+			"Missing guku Cognito Configuration",
+			fmt.Sprintf("A custom endpoint was set, so %s must be set to the Cognito user pool of that guku installation. "+
+				"They may also be set in the selected profile or with the GUKU_COGNITO_USER_POOL_ID, GUKU_COGNITO_CLIENT_ID and GUKU_REGION environment variables.",
+				strings.Join(cognitoConfigAttributes, ", ")),
+		)
+	}
+
+	return diags
+}
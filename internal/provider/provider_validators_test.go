@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testProviderConfig returns a provider configuration with the string
+// attributes in attrs set, the ones in unknown unknown and all others null.
+func testProviderConfig(t *testing.T, attrs map[string]string, unknown ...string) tfsdk.Config {
+	ctx := context.Background()
+
+	schema, diags := New("test")().GetSchema(ctx)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	objectType := schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := map[string]tftypes.Value{}
+	for name, attrType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, val := range attrs {
+		values[name] = tftypes.NewValue(tftypes.String, val)
+	}
+	for _, name := range unknown {
+		values[name] = tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+	}
+
+	return tfsdk.Config{
+		Schema: schema,
+		Raw:    tftypes.NewValue(objectType, values),
+	}
+}
+
+func TestConflictingAttributesValidator(t *testing.T) {
+	validator := &conflictingAttributesValidator{
+		attribute: "api_token",
+		conflicts: []string{"username", "password", "password_file"},
+	}
+
+	testCases := map[string]struct {
+		attrs     map[string]string
+		unknown   []string
+		conflicts int
+	}{
+		"api_token": {
+			attrs: map[string]string{"api_token": "token"},
+		},
+		"username and password": {
+			attrs: map[string]string{"username": "someone", "password": "secret"},
+		},
+		"api_token and password": {
+			attrs:     map[string]string{"api_token": "token", "password": "secret"},
+			conflicts: 1,
+		},
+		// the first conflict is reported
+		"api_token and several conflicts": {
+			attrs:     map[string]string{"api_token": "token", "username": "someone", "password": "secret", "password_file": "password"},
+			conflicts: 1,
+		},
+		"empty api_token": {
+			attrs:     map[string]string{"api_token": "", "username": "someone"},
+			conflicts: 1,
+		},
+		"unknown api_token": {
+			attrs:   map[string]string{"username": "someone"},
+			unknown: []string{"api_token"},
+		},
+		"unknown conflict": {
+			attrs:     map[string]string{"api_token": "token"},
+			unknown:   []string{"username"},
+			conflicts: 1,
+		},
+	}
+
+	for name, testCase := range testCases {
+		resp := &provider.ValidateConfigResponse{}
+		validator.ValidateProvider(context.Background(), provider.ValidateConfigRequest{
+			Config: testProviderConfig(t, testCase.attrs, testCase.unknown...),
+		}, resp)
+
+		if conflicts := resp.Diagnostics.ErrorsCount(); conflicts != testCase.conflicts {
+			t.Errorf("%s: expected %d conflicts, got %v", name, testCase.conflicts, resp.Diagnostics)
+		}
+		for _, diagnostic := range resp.Diagnostics {
+			if diagnostic.Summary() != "Conflicting guku Provider Attributes" {
+				t.Errorf("%s: unexpected diagnostic: %s: %s", name, diagnostic.Summary(), diagnostic.Detail())
+			}
+		}
+	}
+}
+
+func TestCognitoConfigValidator(t *testing.T) {
+	validator := &cognitoConfigValidator{}
+
+	testCases := map[string]struct {
+		attrs   map[string]string
+		unknown []string
+		errors  int
+	}{
+		"unset": {},
+		"set together": {
+			attrs: map[string]string{"cognito_user_pool_id": "eu-west-1_custom", "cognito_client_id": "custom-client", "region": "eu-west-1"},
+		},
+		"user pool without client": {
+			attrs:  map[string]string{"cognito_user_pool_id": "eu-west-1_custom", "region": "eu-west-1"},
+			errors: 1,
+		},
+		"region only": {
+			attrs:  map[string]string{"region": "eu-west-1"},
+			errors: 1,
+		},
+		"unknown client": {
+			attrs:   map[string]string{"cognito_user_pool_id": "eu-west-1_custom", "region": "eu-west-1"},
+			unknown: []string{"cognito_client_id"},
+		},
+		// the user pool may still come from a profile or the environment
+		"custom endpoint": {
+			attrs: map[string]string{"endpoint": "https://guku.example.com/graphql"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		resp := &provider.ValidateConfigResponse{}
+		validator.ValidateProvider(context.Background(), provider.ValidateConfigRequest{
+			Config: testProviderConfig(t, testCase.attrs, testCase.unknown...),
+		}, resp)
+
+		if errors := resp.Diagnostics.ErrorsCount(); errors != testCase.errors {
+			t.Errorf("%s: expected %d errors, got %v", name, testCase.errors, resp.Diagnostics)
+		}
+		for _, diagnostic := range resp.Diagnostics {
+			if diagnostic.Summary() != "Incomplete guku Cognito Configuration" {
+				t.Errorf("%s: unexpected diagnostic: %s: %s", name, diagnostic.Summary(), diagnostic.Detail())
+			}
+		}
+	}
+}
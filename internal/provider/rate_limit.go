@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+// RequestLimiter throttles guku API requests. A single limiter is shared by
+// every resource and data source of a provider instance, so the limits hold
+// regardless of Terraform's parallelism.
+type RequestLimiter struct {
+	// rate is nil when the request rate is unlimited.
+	rate *rate.Limiter
+	// slots is nil when the number of concurrent requests is unlimited.
+	slots chan struct{}
+}
+
+// NewRequestLimiter returns a limiter allowing requestsPerSecond requests per
+// second and maxConcurrent requests in flight. Zero disables either limit.
+func NewRequestLimiter(requestsPerSecond float64, maxConcurrent int) *RequestLimiter {
+	limiter := &RequestLimiter{}
+	if requestsPerSecond > 0 {
+		limiter.rate = rate.NewLimiter(rate.Limit(requestsPerSecond), int(math.Max(1, math.Ceil(requestsPerSecond))))
+	}
+	if maxConcurrent > 0 {
+		limiter.slots = make(chan struct{}, maxConcurrent)
+	}
+	return limiter
+}
+
+// Acquire blocks until a request may be sent, and returns a function that
+// must be called once the request has completed.
+func (l *RequestLimiter) Acquire(ctx context.Context, opName string) (func(), error) {
+	release := func() {}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			tflog.Info(ctx, fmt.Sprintf("Delaying %s, %d guku API requests are already in flight", opName, cap(l.slots)))
+
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		release = func() { <-l.slots }
+	}
+
+	if l.rate != nil {
+		reservation := l.rate.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			tflog.Info(ctx, fmt.Sprintf("Delaying %s by %s to stay within %g guku API requests per second", opName, delay, float64(l.rate.Limit())))
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				reservation.Cancel()
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return release, nil
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRequestLimiter_concurrency(t *testing.T) {
+	limiter := NewRequestLimiter(0, 2)
+
+	var inFlight, maxInFlight int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			release, err := limiter.Acquire(context.Background(), "test")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+
+			current := atomic.AddInt32(&inFlight, 1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", maxInFlight)
+	}
+}
+
+func TestRequestLimiter_rate(t *testing.T) {
+	limiter := NewRequestLimiter(20, 0)
+
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		release, err := limiter.Acquire(context.Background(), "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+
+	// the first 20 requests are allowed as a burst, the other 10 take
+	// 50ms each
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("expected requests to be delayed, took %s", elapsed)
+	}
+}
+
+func TestRequestLimiter_cancelled(t *testing.T) {
+	limiter := NewRequestLimiter(0, 1)
+
+	release, err := limiter.Acquire(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.Acquire(ctx, "test"); err == nil {
+		t.Fatal("expected error when the context is done")
+	}
+}
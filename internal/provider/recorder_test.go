@@ -0,0 +1,481 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/devopzilla/guku-client-go"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// recordEnvVar switches tests using a recorder from replaying their cassette
+// to recording it against the guku API configured with the GUKU_*
+// environment variables. Any way of authenticating works, only the GraphQL
+// requests are recorded and replays authenticate with a placeholder token.
+//
+// Set to recordFake, cassettes are recorded against a fakeGuku serving
+// fakeRecordedPlatform instead, which needs no guku account.
+const recordEnvVar = "GUKU_TEST_RECORD"
+
+const recordFake = "fake"
+
+// fakeRecordedPlatform is the platform bound by tests recorded against a
+// fakeGuku, and fakeRecordedValues the recorder values naming it. It lists
+// no configs, so that the fake accepts any config ID.
+var (
+	fakeRecordedPlatform = guku.Platform{
+		PlatformID:      "tf-acc-platform",
+		PlatformVersion: "v1.0.0",
+		Name:            "tf-acc-platform",
+		MinAPIVersion:   "1.22",
+		MaxAPIVersion:   "1.25",
+	}
+	fakeRecordedValues = map[string]string{
+		"platform_id":        fakeRecordedPlatform.PlatformID,
+		"platform_version":   fakeRecordedPlatform.PlatformVersion,
+		"platform_config_id": "tf-acc-config",
+	}
+)
+
+const redacted = "REDACTED"
+
+// redactedFields are GraphQL variables and response fields that hold
+// credentials. They are never written to cassettes.
+var redactedFields = map[string]bool{
+	"token":              true,
+	"privateTunnelToken": true,
+	"password":           true,
+	"accessToken":        true,
+	"idToken":            true,
+	"refreshToken":       true,
+}
+
+// cassette holds the GraphQL interactions of a test.
+type cassette struct {
+	// Values are test inputs that depend on the recording account, such as
+	// platform IDs, see recorder.Value.
+	Values       map[string]string     `json:"values,omitempty"`
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+type cassetteInteraction struct {
+	OperationName string                 `json:"operationName"`
+	Mutation      bool                   `json:"mutation"`
+	Variables     map[string]interface{} `json:"variables"`
+	StatusCode    int                    `json:"statusCode"`
+	// Response is the decoded JSON response body, or the raw body when it is
+	// not JSON.
+	Response interface{} `json:"response"`
+}
+
+// recorder records the GraphQL requests of a test to a cassette in
+// testdata/cassettes, or replays them from it.
+//
+// Replayed mutations must be issued in the recorded order. Queries are
+// answered with the matching responses recorded after the last replayed
+// mutation, in order, repeating the last one, so that the number of reads
+// Terraform makes may change between Terraform versions.
+type recorder struct {
+	t        *testing.T
+	path     string
+	record   bool
+	cassette cassette
+	// fake is the fakeGuku recorded against, nil when recording against the
+	// guku API.
+	fake *fakeGuku
+
+	mu sync.Mutex
+	// used marks replayed interactions, epoch is the index after the last
+	// replayed mutation.
+	used  []bool
+	epoch int
+}
+
+// newRecorder returns a recorder for the test's cassette. Recorded
+// cassettes are saved when the test finishes, tests whose cassette has not
+// been recorded fail.
+func newRecorder(t *testing.T) *recorder {
+	r := &recorder{
+		t:      t,
+		path:   filepath.Join("testdata", "cassettes", strings.ReplaceAll(t.Name(), "/", "_")+".json"),
+		record: os.Getenv(recordEnvVar) != "",
+	}
+
+	if r.record {
+		if os.Getenv(recordEnvVar) == recordFake {
+			r.fake = newFakeGuku(t)
+			r.fake.AddPlatform(fakeRecordedPlatform)
+		}
+		r.cassette.Values = map[string]string{}
+		t.Cleanup(r.save)
+		return r
+	}
+
+	content, err := os.ReadFile(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("cassette %s has not been recorded, record it with %s=1 against a guku account or %s=%s", r.path, recordEnvVar, recordEnvVar, recordFake)
+	}
+	if err != nil {
+		t.Fatalf("unable to read cassette: %s", err)
+	}
+	if err := json.Unmarshal(content, &r.cassette); err != nil {
+		t.Fatalf("unable to parse cassette %s: %s", r.path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+
+	return r
+}
+
+// Value returns a test input that depends on the recording account. It is
+// read from envVar while recording, from fakeRecordedValues while recording
+// against a fakeGuku and from the cassette while replaying.
+func (r *recorder) Value(name string, envVar string) string {
+	if !r.record {
+		val, ok := r.cassette.Values[name]
+		if !ok {
+			r.t.Fatalf("cassette %s has no value %q", r.path, name)
+		}
+		return val
+	}
+
+	val := os.Getenv(envVar)
+	if r.fake != nil {
+		val = fakeRecordedValues[name]
+	}
+	if val == "" {
+		r.t.Fatalf("%s must be set to record %s", envVar, r.path)
+	}
+	r.cassette.Values[name] = val
+	return val
+}
+
+// ProviderConfig returns the provider block for the test. While recording
+// the provider is configured with the GUKU_* environment variables, or for
+// the fakeGuku.
+func (r *recorder) ProviderConfig() string {
+	if r.fake != nil {
+		return r.fake.ProviderConfig()
+	}
+	if r.record {
+		return `
+provider "guku" {}
+`
+	}
+	return fmt.Sprintf(`
+provider "guku" {
+  endpoint  = %q
+  api_token = "replayed"
+}
+`, DEFAULT_ENDPOINT)
+}
+
+// ProviderFactories returns provider factories that send requests through
+// the recorder. Waits only take time while recording against the guku API.
+func (r *recorder) ProviderFactories() map[string]func() (tfprotov6.ProviderServer, error) {
+	var clock Clock = &fakeClock{}
+	if r.record && r.fake == nil {
+		clock = realClock{}
+	}
+
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"guku": providerserver.NewProtocol6WithError(&GukuProvider{
+			version:       "test",
+			clock:         clock,
+			wrapTransport: r.Wrap,
+		}),
+	}
+}
+
+// Wrap returns a transport that records requests sent through next, or
+// replays them without sending them.
+func (r *recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	return recorderTransport{recorder: r, next: next}
+}
+
+type recorderTransport struct {
+	recorder *recorder
+	next     http.RoundTripper
+}
+
+func (t recorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	var graphqlReq struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	isGraphQL := json.Unmarshal(body, &graphqlReq) == nil && graphqlReq.OperationName != ""
+
+	interaction := cassetteInteraction{
+		OperationName: graphqlReq.OperationName,
+		Mutation:      strings.HasPrefix(strings.TrimSpace(graphqlReq.Query), "mutation"),
+		Variables:     redact(graphqlReq.Variables).(map[string]interface{}),
+	}
+
+	if !t.recorder.record {
+		if !isGraphQL {
+			return nil, fmt.Errorf("unable to replay %s %s, only GraphQL requests are recorded", req.Method, req.URL)
+		}
+		return t.recorder.replay(req, interaction)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || !isGraphQL {
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction.StatusCode = resp.StatusCode
+	interaction.Response = string(respBody)
+	var decoded interface{}
+	if json.Unmarshal(respBody, &decoded) == nil {
+		interaction.Response = redact(decoded)
+	}
+
+	t.recorder.mu.Lock()
+	t.recorder.cassette.Interactions = append(t.recorder.cassette.Interactions, interaction)
+	t.recorder.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *recorder) replay(req *http.Request, interaction cassetteInteraction) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recorded, err := r.find(interaction)
+	if err != nil {
+		return nil, err
+	}
+
+	body, ok := recorded.Response.(string)
+	if !ok {
+		encoded, err := json.Marshal(recorded.Response)
+		if err != nil {
+			return nil, err
+		}
+		body = string(encoded)
+	}
+
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode: recorded.StatusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// find returns the recorded interaction to replay for a request, with r.mu
+// held.
+func (r *recorder) find(interaction cassetteInteraction) (*cassetteInteraction, error) {
+	interactions := r.cassette.Interactions
+	matches := func(i int) bool {
+		return interactions[i].OperationName == interaction.OperationName &&
+			reflect.DeepEqual(interactions[i].Variables, interaction.Variables)
+	}
+
+	if interaction.Mutation {
+		for i := r.epoch; i < len(interactions); i++ {
+			if !interactions[i].Mutation {
+				continue
+			}
+			if !matches(i) {
+				return nil, fmt.Errorf("unable to replay %s %v, expected %s %v", interaction.OperationName, interaction.Variables, interactions[i].OperationName, interactions[i].Variables)
+			}
+			r.used[i] = true
+			r.epoch = i + 1
+			return &interactions[i], nil
+		}
+		return nil, fmt.Errorf("unable to replay %s %v, no more mutations were recorded", interaction.OperationName, interaction.Variables)
+	}
+
+	end := r.epoch
+	for end < len(interactions) && !interactions[end].Mutation {
+		end++
+	}
+
+	// the next unused response since the last mutation, or the last used one
+	last := -1
+	for i := r.epoch; i < end; i++ {
+		if !matches(i) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return &interactions[i], nil
+		}
+		last = i
+	}
+
+	// the latest response before the last mutation, for objects the
+	// mutation did not change
+	if last < 0 {
+		for i := r.epoch - 1; i >= 0; i-- {
+			if matches(i) {
+				last = i
+				break
+			}
+		}
+	}
+
+	if last < 0 {
+		return nil, errors.New("unable to replay " + interaction.OperationName + ", no matching request was recorded")
+	}
+	return &interactions[last], nil
+}
+
+func (r *recorder) save() {
+	if r.t.Failed() || r.t.Skipped() {
+		r.t.Logf("not saving cassette %s of a failed or skipped test", r.path)
+		return
+	}
+
+	content, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		r.t.Errorf("unable to encode cassette: %s", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		r.t.Errorf("unable to save cassette: %s", err)
+		return
+	}
+	if err := os.WriteFile(r.path, append(content, '\n'), 0644); err != nil {
+		r.t.Errorf("unable to save cassette: %s", err)
+	}
+}
+
+// redact returns a copy of a decoded JSON value with redactedFields
+// replaced.
+func redact(val interface{}) interface{} {
+	switch val := val.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for key, field := range val {
+			if redactedFields[key] && field != nil {
+				result[key] = redacted
+				continue
+			}
+			result[key] = redact(field)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, elem := range val {
+			result[i] = redact(elem)
+		}
+		return result
+	}
+	return val
+}
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeGuku(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	newTestClient := func(r *recorder) *Client {
+		return NewClient(fake.Endpoint(), &APITokenCredentials{Token: fakeGukuAPIToken}, ClientOptions{
+			HTTPClient: &http.Client{Transport: r.Wrap(http.DefaultTransport)},
+		})
+	}
+
+	// record
+	recording := &recorder{t: t, path: path, record: true}
+	client := newTestClient(recording)
+
+	clusterContext := "{}"
+	created, err := client.CreateCluster(ctx, "demo", "https://one.example.com", "ca", "secret-token", "1.24", &clusterContext)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	id := created.GetClusterID()
+	if _, err := client.GetCluster(ctx, id); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	server := "https://two.example.com"
+	if _, err := client.UpdateCluster(ctx, id, nil, &server, nil, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := client.GetCluster(ctx, id); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	recording.save()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "secret-token") || strings.Contains(string(content), fakeGukuAPIToken) {
+		t.Fatalf("expected credentials to be redacted, got %s", content)
+	}
+
+	// replay, reading more often than recorded
+	replaying := &recorder{t: t, path: path}
+	if err := json.Unmarshal(content, &replaying.cassette); err != nil {
+		t.Fatal(err)
+	}
+	replaying.used = make([]bool, len(replaying.cassette.Interactions))
+	client = newTestClient(replaying)
+	fake.server.Close()
+
+	if _, err := client.CreateCluster(ctx, "demo", "https://one.example.com", "ca", "other-token", "1.24", &clusterContext); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		cluster, err := client.GetCluster(ctx, id)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if *cluster.GetServer() != "https://one.example.com" {
+			t.Errorf("expected server before the update, got %s", *cluster.GetServer())
+		}
+	}
+
+	if _, err := client.DeleteCluster(ctx, id); err == nil {
+		t.Error("expected mutations out of the recorded order to fail")
+	}
+
+	if _, err := client.UpdateCluster(ctx, id, nil, &server, nil, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cluster, err := client.GetCluster(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if *cluster.GetServer() != server {
+		t.Errorf("expected server after the update, got %s", *cluster.GetServer())
+	}
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// RetryPolicy controls how failed guku API requests are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Jitter is the fraction of each delay that is randomized, between 0
+	// and 1.
+	Jitter float64
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.2,
+}
+
+// Delay returns how long to wait before the given retry, counting from 1,
+// using exponential backoff capped at MaxDelay.
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(retry-1))
+	if delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	delay -= delay * p.Jitter * rand.Float64()
+	return time.Duration(delay)
+}
+
+// APIError is returned for guku API responses with a non-200 status code.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("guku API returned %s: %s", e.Status, e.Body)
+}
+
+// throttledGraphQLErrors are fragments of GraphQL error messages AppSync
+// returns for requests it rejected without processing them.
+var throttledGraphQLErrors = []string{
+	"throttl",
+	"rate exceeded",
+	"toomanyrequests",
+	"too many requests",
+}
+
+// unavailableGraphQLErrors are fragments of GraphQL error messages AppSync
+// returns for transiently failing requests, which may have been processed.
+var unavailableGraphQLErrors = []string{
+	"serviceunavailable",
+	"service unavailable",
+	"internalfailure",
+}
+
+// NotFoundError is returned for objects that do not exist, by the in-memory
+// backend and by waiters for objects the guku API stopped reporting.
+type NotFoundError struct {
+	Kind string
+	ID   string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Kind, e.ID)
+}
+
+// notFoundErrorType is the GraphQL error type of the guku API for objects that
+// do not exist, which AppSync also prefixes messages of Lambda errors with.
+const notFoundErrorType = "NotFoundException"
+
+// IsNotFound reports whether a failed request was rejected because the object
+// it refers to does not exist, for example after it was deleted in the guku
+// UI. Reads of missing objects may also succeed with null instead.
+//
+// Only the not found errors of the guku API itself are matched. A 404 status
+// comes from the endpoint rather than the object, and is never taken as one.
+func IsNotFound(err error) bool {
+	var notFoundErr *NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return true
+	}
+
+	var gqlErrs gqlerror.List
+	if errors.As(err, &gqlErrs) {
+		for _, gqlErr := range gqlErrs {
+			if gqlErr.Extensions[errorTypeExtension] == notFoundErrorType || strings.HasPrefix(gqlErr.Message, notFoundErrorType+":") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsRetryable reports whether a failed request may be retried. Mutations are
+// only retried when the API rejected them without processing them, so that a
+// create is never issued twice.
+func IsRetryable(err error, mutation bool) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return true
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return !mutation
+		}
+		return false
+	}
+
+	var gqlErrs gqlerror.List
+	if errors.As(err, &gqlErrs) {
+		for _, gqlErr := range gqlErrs {
+			message := strings.ToLower(gqlErr.Message)
+			if containsAny(message, throttledGraphQLErrors) {
+				return true
+			}
+			if containsAny(message, unavailableGraphQLErrors) {
+				return !mutation
+			}
+		}
+		return false
+	}
+
+	// connection failures and timeouts, but not other failures that
+	// net/http wraps, such as missing credentials
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return !mutation
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return !mutation
+	}
+
+	return false
+}
+
+// containsAny reports whether s contains any of the fragments.
+func containsAny(s string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if strings.Contains(s, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	for retry, expected := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 5 * time.Second,
+		9: 5 * time.Second,
+	} {
+		if delay := policy.Delay(retry); delay != expected {
+			t.Errorf("retry %d: expected %s, got %s", retry, expected, delay)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if delay := policy.Delay(1); delay < 500*time.Millisecond || delay > time.Second {
+			t.Fatalf("expected jittered delay between 500ms and 1s, got %s", delay)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		query    bool
+		mutation bool
+	}{
+		"throttled": {
+			err:      &APIError{StatusCode: http.StatusTooManyRequests},
+			query:    true,
+			mutation: true,
+		},
+		"unavailable": {
+			err:   &APIError{StatusCode: http.StatusServiceUnavailable},
+			query: true,
+		},
+		"bad request": {
+			err: &APIError{StatusCode: http.StatusBadRequest},
+		},
+		"graphql throttled": {
+			err:      gqlerror.List{{Message: "Rate exceeded"}},
+			query:    true,
+			mutation: true,
+		},
+		"graphql throttled mutation": {
+			err:      gqlerror.List{{Message: "TooManyRequestsException: too many requests for createCluster"}},
+			query:    true,
+			mutation: true,
+		},
+		// the mutation may have been processed before it failed
+		"graphql unavailable": {
+			err:   gqlerror.List{{Message: "Service Unavailable"}},
+			query: true,
+		},
+		"graphql internal failure": {
+			err:   gqlerror.List{{Message: "InternalFailure: An internal failure occurred"}},
+			query: true,
+		},
+		"graphql validation": {
+			err: gqlerror.List{{Message: "Validation error of type WrongType: argument 'input.name'"}},
+		},
+		"wrapped": {
+			err:      fmt.Errorf("request failed: %w", &APIError{StatusCode: http.StatusTooManyRequests}),
+			query:    true,
+			mutation: true,
+		},
+		"other": {
+			err: errors.New("boom"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		if retryable := IsRetryable(testCase.err, false); retryable != testCase.query {
+			t.Errorf("%s: expected query retryable %t, got %t", name, testCase.query, retryable)
+		}
+		if retryable := IsRetryable(testCase.err, true); retryable != testCase.mutation {
+			t.Errorf("%s: expected mutation retryable %t, got %t", name, testCase.mutation, retryable)
+		}
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		notFound bool
+	}{
+		"not found": {
+			err:      &NotFoundError{Kind: "cluster", ID: "cluster-1"},
+			notFound: true,
+		},
+		"graphql error type": {
+			err:      gqlerror.List{{Message: "Cluster cluster-1 not found", Extensions: map[string]interface{}{"errorType": "NotFoundException"}}},
+			notFound: true,
+		},
+		"graphql error type message": {
+			err:      gqlerror.List{{Message: "NotFoundException: no such platform binding"}},
+			notFound: true,
+		},
+		"wrapped": {
+			err:      fmt.Errorf("request failed: %w", gqlerror.List{{Message: "NotFoundException: no such cluster"}}),
+			notFound: true,
+		},
+		// the endpoint rather than the object is missing
+		"status": {
+			err: &APIError{StatusCode: http.StatusNotFound},
+		},
+		"graphql message": {
+			err: gqlerror.List{{Message: "Cluster cluster-1 not found"}},
+		},
+		"graphql other error type": {
+			err: gqlerror.List{{Message: "Platform config does not exist", Extensions: map[string]interface{}{"errorType": "Lambda:Unhandled"}}},
+		},
+		"unauthorized": {
+			err: &APIError{StatusCode: http.StatusUnauthorized},
+		},
+		"graphql validation": {
+			err: gqlerror.List{{Message: "Validation error of type WrongType: argument 'clusterID'"}},
+		},
+		"other": {
+			err: errors.New("boom"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		if notFound := IsNotFound(testCase.err); notFound != testCase.notFound {
+			t.Errorf("%s: expected not found %t, got %t", name, testCase.notFound, notFound)
+		}
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"getCluster":{"clusterID":"c1","name":"demo"}}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, &APITokenCredentials{Token: "test"}, ClientOptions{
+		RetryPolicy: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
+
+	cluster, err := client.GetCluster(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cluster.GetName() != "demo" || requests != 3 {
+		t.Errorf("expected cluster after 3 requests, got %+v after %d", cluster, requests)
+	}
+}
+
+func TestClientDoesNotRetryValidationErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"data":null,"errors":[{"message":"Validation error of type WrongType"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, &APITokenCredentials{Token: "test"}, ClientOptions{
+		RetryPolicy: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
+
+	if _, err := client.DeleteCluster(context.Background(), "c1"); err == nil {
+		t.Fatal("expected error")
+	}
+	if requests != 1 {
+		t.Errorf("expected a single request, got %d", requests)
+	}
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+var updateSchemas = flag.Bool("update-schemas", false, "update the schema golden files in testdata/schemas")
+
+// goldenBlock is the part of a schema that configurations depend on.
+// Descriptions are left out, so that only changes that can break
+// configurations or state need the golden files to be updated.
+type goldenBlock struct {
+	Attributes map[string]goldenAttribute   `json:"attributes,omitempty"`
+	Blocks     map[string]goldenNestedBlock `json:"blocks,omitempty"`
+}
+
+type goldenAttribute struct {
+	Type      json.RawMessage `json:"type"`
+	Required  bool            `json:"required,omitempty"`
+	Optional  bool            `json:"optional,omitempty"`
+	Computed  bool            `json:"computed,omitempty"`
+	Sensitive bool            `json:"sensitive,omitempty"`
+}
+
+type goldenNestedBlock struct {
+	Nesting  string      `json:"nesting"`
+	MinItems int64       `json:"min_items,omitempty"`
+	MaxItems int64       `json:"max_items,omitempty"`
+	Block    goldenBlock `json:"block"`
+}
+
+func newGoldenBlock(t *testing.T, block *tfprotov6.SchemaBlock) goldenBlock {
+	golden := goldenBlock{
+		Attributes: map[string]goldenAttribute{},
+		Blocks:     map[string]goldenNestedBlock{},
+	}
+
+	for _, attribute := range block.Attributes {
+		attributeType, err := attribute.Type.MarshalJSON()
+		if err != nil {
+			t.Fatalf("unable to encode type of %s: %s", attribute.Name, err)
+		}
+		golden.Attributes[attribute.Name] = goldenAttribute{
+			Type:      attributeType,
+			Required:  attribute.Required,
+			Optional:  attribute.Optional,
+			Computed:  attribute.Computed,
+			Sensitive: attribute.Sensitive,
+		}
+	}
+
+	for _, nested := range block.BlockTypes {
+		golden.Blocks[nested.TypeName] = goldenNestedBlock{
+			Nesting:  nested.Nesting.String(),
+			MinItems: nested.MinItems,
+			MaxItems: nested.MaxItems,
+			Block:    newGoldenBlock(t, nested.Block),
+		}
+	}
+
+	return golden
+}
+
+// TestSchemaGolden compares the provider, resource and data source schemas
+// with the golden files in testdata/schemas. After an intended schema change,
+// update them with:
+//
+//	go test ./internal/provider -run TestSchemaGolden -update-schemas
+func TestSchemaGolden(t *testing.T) {
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, diagnostic := range resp.Diagnostics {
+		t.Fatalf("unexpected diagnostic: %s: %s", diagnostic.Summary, diagnostic.Detail)
+	}
+
+	schemas := map[string]*tfprotov6.Schema{
+		"provider": resp.Provider,
+	}
+	for name, schema := range resp.ResourceSchemas {
+		schemas["resource_"+name] = schema
+	}
+	for name, schema := range resp.DataSourceSchemas {
+		schemas["data_source_"+name] = schema
+	}
+
+	for name, schema := range schemas {
+		content, err := json.MarshalIndent(newGoldenBlock(t, schema.Block), "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content = append(content, '\n')
+
+		path := filepath.Join("testdata", "schemas", name+".json")
+		if *updateSchemas {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		expected, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s: unable to read golden file, a new schema needs -update-schemas: %s", name, err)
+			continue
+		}
+		if string(expected) != string(content) {
+			t.Errorf("%s schema does not match %s. If the change is intended and does not break existing configurations, "+
+				"update the golden file with -update-schemas.\n\ngot:\n%s", name, path, content)
+		}
+	}
+}
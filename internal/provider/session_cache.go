@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// SessionCache persists Cognito sessions on disk so that every provider start
+// does not have to go through the full SRP login.
+type SessionCache struct {
+	dir string
+}
+
+// DefaultSessionCache returns the session cache in ~/.guku/sessions, or in
+// GUKU_SESSION_CACHE_DIR when set.
+func DefaultSessionCache() (*SessionCache, error) {
+	if dir := os.Getenv("GUKU_SESSION_CACHE_DIR"); dir != "" {
+		return &SessionCache{dir: dir}, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return &SessionCache{dir: filepath.Join(home, ".guku", "sessions")}, nil
+}
+
+// filename returns the cache file for a session, keyed by endpoint, user pool
+// and username. The key is hashed so it is safe to use as a file name.
+func (c *SessionCache) filename(endpoint string, cognito CognitoConfig, username string) string {
+	sum := sha256.Sum256([]byte(endpoint + "\n" + cognito.UserPoolID + "\n" + cognito.ClientID + "\n" + username))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Load returns the cached session for username at endpoint.
+func (c *SessionCache) Load(endpoint string, cognito CognitoConfig, username string) (*CognitoCredentials, error) {
+	content, err := os.ReadFile(c.filename(endpoint, cognito, username))
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := &CognitoCredentials{Cognito: cognito}
+	if err := json.Unmarshal(content, credentials); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// Store saves the session for username at endpoint, readable only by the
+// current user.
+func (c *SessionCache) Store(endpoint string, cognito CognitoConfig, username string, credentials *CognitoCredentials) error {
+	content, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that concurrent providers never
+	// read a partially written session
+	f, err := os.CreateTemp(c.dir, ".session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.filename(endpoint, cognito, username))
+}
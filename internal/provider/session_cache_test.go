@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSessionCache(t *testing.T) {
+	cache := &SessionCache{dir: filepath.Join(t.TempDir(), "sessions")}
+	expiry := time.Now().Add(time.Hour).Round(time.Second)
+
+	err := cache.Store("https://guku.example/graphql", fakeCognitoConfig, "alice", &CognitoCredentials{
+		IDToken:      "id",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		Expiry:       expiry,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	credentials, err := cache.Load("https://guku.example/graphql", fakeCognitoConfig, "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if credentials.IDToken != "id" || credentials.AccessToken != "access" || credentials.RefreshToken != "refresh" {
+		t.Errorf("expected stored tokens, got %+v", credentials)
+	}
+	if !credentials.Expiry.Equal(expiry) {
+		t.Errorf("expected expiry %s, got %s", expiry, credentials.Expiry)
+	}
+	if credentials.Cognito != fakeCognitoConfig {
+		t.Errorf("expected user pool %+v, got %+v", fakeCognitoConfig, credentials.Cognito)
+	}
+
+	// sessions are kept apart per endpoint, user pool and username
+	otherPool := fakeCognitoConfig
+	otherPool.UserPoolID = "eu-west-1_other"
+	for name, load := range map[string]func() (*CognitoCredentials, error){
+		"endpoint": func() (*CognitoCredentials, error) {
+			return cache.Load("https://other.example/graphql", fakeCognitoConfig, "alice")
+		},
+		"user pool": func() (*CognitoCredentials, error) {
+			return cache.Load("https://guku.example/graphql", otherPool, "alice")
+		},
+		"username": func() (*CognitoCredentials, error) {
+			return cache.Load("https://guku.example/graphql", fakeCognitoConfig, "bob")
+		},
+	} {
+		if _, err := load(); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: expected no cached session, got %v", name, err)
+		}
+	}
+}
+
+func TestSessionCache_permissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on windows")
+	}
+
+	dir := filepath.Join(t.TempDir(), "sessions")
+	cache := &SessionCache{dir: dir}
+
+	if err := cache.Store("https://guku.example/graphql", fakeCognitoConfig, "alice", &CognitoCredentials{IDToken: "id"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("expected cache directory mode 0700, got %o", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected a single session file, got %d entries", len(entries))
+	}
+	info, err = entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected session file mode 0600, got %o", info.Mode().Perm())
+	}
+}
+
+func TestCognitoSession_login(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GUKU_SESSION_CACHE_DIR", dir)
+
+	cognito := newFakeCognito(t)
+	cognito.AddUser("alice", "secret")
+	data := testProviderModel(map[string]string{
+		"endpoint":             "https://guku.example/graphql",
+		"cognito_user_pool_id": fakeCognitoConfig.UserPoolID,
+		"cognito_client_id":    fakeCognitoConfig.ClientID,
+		"region":               fakeCognitoConfig.Region,
+		"username":             "alice",
+		"password":             "secret",
+	})
+
+	credentials, err := cognitoSession(context.Background(), data, cognito.HTTPClient())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !cognito.ValidIDToken(credentials.IDToken) {
+		t.Errorf("expected issued id token, got %q", credentials.IDToken)
+	}
+
+	// the new session is cached for the next run
+	cached, err := (&SessionCache{dir: dir}).Load(data.Endpoint.Value, fakeCognitoConfig, "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cached.IDToken != credentials.IDToken || cached.RefreshToken != credentials.RefreshToken {
+		t.Errorf("expected new session to be cached, got %+v", cached)
+	}
+}
+
+func TestCognitoSession_cached(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GUKU_SESSION_CACHE_DIR", dir)
+
+	cognito := newFakeCognito(t)
+	data := testProviderModel(map[string]string{
+		"endpoint": "https://guku.example/graphql",
+		"username": "alice",
+		"password": "secret",
+	})
+
+	cache := &SessionCache{dir: dir}
+	err := cache.Store(data.Endpoint.Value, DefaultCognitoConfig, "alice", &CognitoCredentials{
+		IDToken:      "cached",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credentials, err := cognitoSession(context.Background(), data, cognito.HTTPClient())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if credentials.IDToken != "cached" {
+		t.Errorf("expected cached session, got id token %q", credentials.IDToken)
+	}
+	if n := cognito.Requests("REFRESH_TOKEN_AUTH"); n != 0 {
+		t.Errorf("expected a valid cached session not to be refreshed, got %d refreshes", n)
+	}
+}
+
+func TestCognitoSession_refreshExpired(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GUKU_SESSION_CACHE_DIR", dir)
+
+	cognito := newFakeCognito(t)
+	cognito.AddRefreshToken("refresh", "alice")
+	data := testProviderModel(map[string]string{
+		"endpoint": "https://guku.example/graphql",
+		"username": "alice",
+		"password": "secret",
+	})
+
+	cache := &SessionCache{dir: dir}
+	err := cache.Store(data.Endpoint.Value, DefaultCognitoConfig, "alice", &CognitoCredentials{
+		IDToken:      "expired",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credentials, err := cognitoSession(context.Background(), data, cognito.HTTPClient())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if credentials.IDToken != "id-alice-1" {
+		t.Errorf("expected refreshed session, got id token %q", credentials.IDToken)
+	}
+	if n := cognito.Requests("REFRESH_TOKEN_AUTH"); n != 1 {
+		t.Errorf("expected a single refresh, got %d", n)
+	}
+
+	// the refreshed session is written back to the cache, keeping the
+	// refresh token Cognito did not rotate
+	cached, err := cache.Load(data.Endpoint.Value, DefaultCognitoConfig, "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cached.IDToken != "id-alice-1" || cached.RefreshToken != "refresh" {
+		t.Errorf("expected refreshed session to be cached, got %+v", cached)
+	}
+	if !cached.Expiry.After(time.Now()) {
+		t.Errorf("expected cached session to be valid, expires %s", cached.Expiry)
+	}
+}
@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// testAccNamePrefix starts the names of all clusters created by acceptance
+// tests against a real guku account, so that the sweepers can find them.
+const testAccNamePrefix = "tf-acc-"
+
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+// Sweepers delete objects left behind by aborted acceptance test runs. They
+// configure the provider from the environment like an empty provider block
+// would, run them with
+//
+//	go test ./internal/provider -v -sweep=all
+//
+// and set GUKU_ENDPOINT=memory://<path> to sweep an in-memory backend.
+func init() {
+	resource.AddTestSweepers("guku_platform_binding", &resource.Sweeper{
+		Name: "guku_platform_binding",
+		F:    sweepPlatformBindings,
+	})
+	resource.AddTestSweepers("guku_cluster", &resource.Sweeper{
+		Name:         "guku_cluster",
+		Dependencies: []string{"guku_platform_binding"},
+		F:            sweepClusters,
+	})
+}
+
+func sweepPlatformBindings(region string) error {
+	ctx := context.Background()
+
+	data, err := sweeperProviderData(ctx)
+	if err != nil {
+		return err
+	}
+
+	clusters, err := data.Client.ListClusters(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list clusters: %w", err)
+	}
+
+	var errs []string
+	deleted := map[string]string{}
+	for _, cluster := range clusters {
+		if !strings.HasPrefix(cluster.Name, testAccNamePrefix) {
+			continue
+		}
+		for _, binding := range cluster.Bindings {
+			log.Printf("[INFO] Deleting platform binding %s of cluster %s (%s)", binding.PlatformBindingID, cluster.ClusterID, cluster.Name)
+			if _, err := data.Client.DeletePlatformBinding(ctx, cluster.ClusterID, binding.PlatformBindingID); err != nil {
+				errs = append(errs, fmt.Sprintf("platform binding %s: %s", binding.PlatformBindingID, err))
+				continue
+			}
+			deleted[binding.PlatformBindingID] = cluster.ClusterID
+		}
+	}
+
+	// clusters can only be deleted once their platforms are uninstalled
+	for bindingID, clusterID := range deleted {
+		gone, err := data.Waiter.PollUntil(ctx, platformBindingDeleteTimeout, func(ctx context.Context, poll int) (bool, error) {
+			binding, err := data.Client.GetPlatformBinding(ctx, clusterID, bindingID)
+			if IsNotFound(err) {
+				return true, nil
+			}
+			return binding == nil, err
+		})
+		if err == nil && !gone {
+			err = fmt.Errorf("still reported after %s", platformBindingDeleteTimeout)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("platform binding %s: %s", bindingID, err))
+		}
+	}
+
+	return sweepError(errs)
+}
+
+func sweepClusters(region string) error {
+	ctx := context.Background()
+
+	data, err := sweeperProviderData(ctx)
+	if err != nil {
+		return err
+	}
+
+	clusters, err := data.Client.ListClusters(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list clusters: %w", err)
+	}
+
+	var errs []string
+	for _, cluster := range clusters {
+		if !strings.HasPrefix(cluster.Name, testAccNamePrefix) {
+			continue
+		}
+		log.Printf("[INFO] Deleting cluster %s (%s)", cluster.ClusterID, cluster.Name)
+		if _, err := data.Client.DeleteCluster(ctx, cluster.ClusterID); err != nil {
+			errs = append(errs, fmt.Sprintf("cluster %s: %s", cluster.ClusterID, err))
+		}
+	}
+
+	return sweepError(errs)
+}
+
+func sweepError(errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("unable to sweep %d objects:\n%s", len(errs), strings.Join(errs, "\n"))
+}
+
+// sweeperProviderData configures the provider with all attributes unset, so
+// that the endpoint and credentials are read from the environment or profile.
+func sweeperProviderData(ctx context.Context) (*ProviderData, error) {
+	p := New("sweeper")()
+
+	schema, diags := p.GetSchema(ctx)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unable to get provider schema: %v", diags)
+	}
+
+	objectType := schema.Type().TerraformType(ctx).(tftypes.Object)
+	attrs := map[string]tftypes.Value{}
+	for name, attrType := range objectType.AttributeTypes {
+		attrs[name] = tftypes.NewValue(attrType, nil)
+	}
+
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schema,
+			Raw:    tftypes.NewValue(objectType, attrs),
+		},
+	}, resp)
+	if resp.Diagnostics.HasError() {
+		return nil, fmt.Errorf("unable to configure provider: %v", resp.Diagnostics)
+	}
+
+	return resp.ResourceData.(*ProviderData), nil
+}
+
+func TestSweepers(t *testing.T) {
+	ctx := context.Background()
+	endpoint := MemoryEndpointPrefix + filepath.Join(t.TempDir(), "state.json")
+	t.Setenv("GUKU_ENDPOINT", endpoint)
+	t.Setenv("GUKU_PROFILE", "")
+
+	client, err := NewMemoryClient(endpoint, testMemoryCatalog)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var ids []string
+	for _, name := range []string{testAccNamePrefix + "cluster", "production"} {
+		cluster, err := client.CreateCluster(ctx, name, "", "", "token", "1.24", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := client.CreatePlatformBinding(ctx, cluster.ClusterID, "platform-1", "v1", "config-1"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ids = append(ids, cluster.ClusterID)
+	}
+
+	// clusters still holding bindings cannot be deleted
+	if err := sweepClusters(""); err == nil {
+		t.Error("expected error sweeping clusters before their platform bindings")
+	}
+
+	if err := sweepPlatformBindings(""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := sweepClusters(""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client, err = NewMemoryClient(endpoint, testMemoryCatalog)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cluster, _ := client.GetCluster(ctx, ids[0]); cluster != nil {
+		t.Errorf("expected test cluster to be swept, got %+v", cluster)
+	}
+	if cluster, _ := client.GetCluster(ctx, ids[1]); cluster == nil || len(cluster.Bindings) != 1 {
+		t.Errorf("expected other cluster to be kept with its binding, got %+v", cluster)
+	}
+}
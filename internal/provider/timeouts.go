@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TimeoutsModel describes the timeouts block of resources. A nil
+// *TimeoutsModel, for a resource without the block, uses the defaults.
+type TimeoutsModel struct {
+	Create types.String `tfsdk:"create"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+// timeoutsBlock returns the schema of the timeouts block, describing the
+// default of each timeout in markdown.
+func timeoutsBlock(createDefault string, updateDefault string, deleteDefault string) tfsdk.Block {
+	return tfsdk.Block{
+		MarkdownDescription: "How long to wait for operations on the guku API to settle.",
+		NestingMode:         tfsdk.BlockNestingModeSingle,
+		Attributes: map[string]tfsdk.Attribute{
+			"create": {
+				MarkdownDescription: fmt.Sprintf("Timeout for creation, such as `10m`. Defaults to %s.", createDefault),
+				Optional:            true,
+				Type:                types.StringType,
+				Validators:          []tfsdk.AttributeValidator{timeoutValidator{}},
+			},
+			"update": {
+				MarkdownDescription: fmt.Sprintf("Timeout for updates, such as `10m`. Defaults to %s.", updateDefault),
+				Optional:            true,
+				Type:                types.StringType,
+				Validators:          []tfsdk.AttributeValidator{timeoutValidator{}},
+			},
+			"delete": {
+				MarkdownDescription: fmt.Sprintf("Timeout for deletion, such as `10m`. When set, deletion fails if the guku API still reports the object after it. Defaults to %s, after which deletion succeeds with a warning.", deleteDefault),
+				Optional:            true,
+				Type:                types.StringType,
+				Validators:          []tfsdk.AttributeValidator{timeoutValidator{}},
+			},
+		},
+	}
+}
+
+// CreateTimeout returns the create timeout, or def when it is not set.
+func (t *TimeoutsModel) CreateTimeout(def time.Duration) (time.Duration, diag.Diagnostics) {
+	if t == nil {
+		return def, nil
+	}
+	return parseTimeout(path.Root("timeouts").AtName("create"), t.Create, def)
+}
+
+// UpdateTimeout returns the update timeout, or def when it is not set.
+func (t *TimeoutsModel) UpdateTimeout(def time.Duration) (time.Duration, diag.Diagnostics) {
+	if t == nil {
+		return def, nil
+	}
+	return parseTimeout(path.Root("timeouts").AtName("update"), t.Update, def)
+}
+
+// DeleteTimeout returns the delete timeout, or def when it is not set.
+func (t *TimeoutsModel) DeleteTimeout(def time.Duration) (time.Duration, diag.Diagnostics) {
+	if t == nil {
+		return def, nil
+	}
+	return parseTimeout(path.Root("timeouts").AtName("delete"), t.Delete, def)
+}
+
+// UpdateSet reports whether the update timeout is set in the configuration.
+func (t *TimeoutsModel) UpdateSet() bool {
+	return t != nil && !t.Update.IsNull()
+}
+
+// DeleteSet reports whether the delete timeout is set in the configuration.
+func (t *TimeoutsModel) DeleteSet() bool {
+	return t != nil && !t.Delete.IsNull()
+}
+
+func parseTimeout(attrPath path.Path, val types.String, def time.Duration) (time.Duration, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if val.IsNull() || val.IsUnknown() {
+		return def, diags
+	}
+
+	timeout, err := time.ParseDuration(val.Value)
+	if err != nil || timeout < 0 {
+		diags.AddAttributeError(
+			attrPath,
+			"Invalid Timeout",
+			fmt.Sprintf("%s must be a positive duration such as \"30s\" or \"10m\", got: %s", attrPath, val.Value),
+		)
+	}
+	return timeout, diags
+}
+
+// timeoutValidator checks timeouts when planning, rather than only once the
+// operation using them runs.
+type timeoutValidator struct{}
+
+func (v timeoutValidator) Description(ctx context.Context) string {
+	return "must be a positive duration"
+}
+
+func (v timeoutValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v timeoutValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var val types.String
+
+	resp.Diagnostics.Append(tfsdk.ValueAs(ctx, req.AttributeConfig, &val)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, diags := parseTimeout(req.AttributePath, val, 0)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TransportOptions configures the HTTP client used for the guku API and for
+// authentication.
+type TransportOptions struct {
+	// ProxyURL overrides the proxy from the HTTPS_PROXY and NO_PROXY
+	// environment variables.
+	ProxyURL string
+	// CABundle holds PEM encoded certificates trusted in addition to the
+	// system roots.
+	CABundle           []byte
+	InsecureSkipVerify bool
+	// Timeout limits each request, zero means no timeout.
+	Timeout time.Duration
+}
+
+// NewHTTPClient returns an HTTP client configured with options.
+func NewHTTPClient(options TransportOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if options.ProxyURL != "" {
+		proxyURL, err := url.Parse(options.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// the user explicitly asked to skip verification, a warning is
+		// shown when the provider is configured
+		InsecureSkipVerify: options.InsecureSkipVerify,
+	}
+
+	if len(options.CABundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(options.CABundle) {
+			return nil, errors.New("no PEM encoded certificates found in CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   options.Timeout,
+	}, nil
+}
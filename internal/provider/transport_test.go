@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClient_caBundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	client, err := NewHTTPClient(TransportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("expected untrusted certificate to be rejected")
+	}
+
+	client, err = NewHTTPClient(TransportOptions{CABundle: caBundle, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected certificate from CA bundle to be trusted, got: %s", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewHTTPClient_invalidCABundle(t *testing.T) {
+	if _, err := NewHTTPClient(TransportOptions{CABundle: []byte("not a certificate")}); err == nil {
+		t.Fatal("expected error for invalid CA bundle")
+	}
+}
+
+func TestNewHTTPClient_proxy(t *testing.T) {
+	client, err := NewHTTPClient(TransportOptions{ProxyURL: "http://proxy.example.com:3128"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://guku.example.com/graphql", nil)
+	proxyURL, err := client.Transport.(*http.Transport).Proxy(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proxyURL.String() != "http://proxy.example.com:3128" {
+		t.Errorf("expected configured proxy, got %s", proxyURL)
+	}
+}
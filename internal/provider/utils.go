@@ -2,8 +2,13 @@ package provider
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -15,6 +20,15 @@ func StringValueOrNull(val *string) types.String {
 	}
 }
 
+// StringValueOrNullIfEmpty is StringValueOrNull for required API fields, which
+// hold empty strings when the optional attribute they are set from is not.
+func StringValueOrNullIfEmpty(val *string) types.String {
+	if val == nil || *val == "" {
+		return types.String{Null: true}
+	}
+	return types.String{Value: *val}
+}
+
 func ValueStringOrNull(val types.String) *string {
 	if val.IsNull() {
 		return nil
@@ -23,6 +37,15 @@ func ValueStringOrNull(val types.String) *string {
 	}
 }
 
+// stringValue returns the value of an optional API field, or an empty string
+// when it is not set.
+func stringValue(val *string) string {
+	if val == nil {
+		return ""
+	}
+	return *val
+}
+
 func MinifyJSONString(val string) string {
 	compactContext := &bytes.Buffer{}
 	if err := json.Compact(compactContext, []byte(val)); err != nil {
@@ -30,3 +53,55 @@ func MinifyJSONString(val string) string {
 	}
 	return compactContext.String()
 }
+
+// JSONSemanticallyEqual reports whether two JSON documents hold the same
+// value, regardless of formatting and key order. Invalid JSON is never equal.
+func JSONSemanticallyEqual(a string, b string) bool {
+	var aVal, bVal interface{}
+	if err := json.Unmarshal([]byte(a), &aVal); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &bVal); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(aVal, bVal)
+}
+
+// stringFallback returns val if it is set, otherwise the first non-empty
+// fallback. The result is null when none of them are set.
+func stringFallback(val types.String, fallbacks ...string) types.String {
+	if !val.IsNull() {
+		return val
+	}
+	for _, fallback := range fallbacks {
+		if fallback != "" {
+			return types.String{Value: fallback}
+		}
+	}
+	return val
+}
+
+// sleep waits for d, returning early with the context error once ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ClientErrorDiagnostic returns the diagnostic for a failed client call or
+// wait, reporting cancelled operations separately from API errors.
+func ClientErrorDiagnostic(ctx context.Context, summary string, err error) diag.Diagnostic {
+	if ctx.Err() != nil {
+		return diag.NewErrorDiagnostic(
+			"Operation Cancelled",
+			fmt.Sprintf("%s, the operation was cancelled: %s", summary, ctx.Err()),
+		)
+	}
+	return diag.NewErrorDiagnostic("Client Error", fmt.Sprintf("%s, got error: %s", summary, err))
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Default timeouts of resources that poll the guku API. Platform bindings
+// are created and updated within the polling policy's Timeout by default,
+// and cluster updates are only bounded by a timeout set in the configuration.
+// Deletions fail once their timeout passes only when it is set, and succeed
+// with a warning after the default.
+const (
+	clusterCreateTimeout         = 40 * time.Second
+	clusterDeleteTimeout         = 30 * time.Second
+	platformBindingDeleteTimeout = 2 * time.Minute
+)
+
+// PollPolicy controls how resources poll the guku API for the result of
+// asynchronous operations, such as a platform binding leaving Pending.
+type PollPolicy struct {
+	// MaxAttempts is the maximum number of polls within the default
+	// Timeout.
+	MaxAttempts int
+	Interval    time.Duration
+	MaxInterval time.Duration
+	// Backoff multiplies the interval after every poll, 1 polls at a fixed
+	// interval.
+	Backoff float64
+}
+
+var DefaultPollPolicy = PollPolicy{
+	MaxAttempts: 20,
+	Interval:    30 * time.Second,
+	MaxInterval: 5 * time.Minute,
+	Backoff:     1,
+}
+
+// Delay returns how long to wait after the given poll, counting from 1.
+func (p PollPolicy) Delay(poll int) time.Duration {
+	delay := float64(p.Interval)
+	for i := 1; i < poll && delay < float64(p.MaxInterval); i++ {
+		delay *= p.Backoff
+	}
+	if delay > float64(p.MaxInterval) {
+		delay = float64(p.MaxInterval)
+	}
+	return time.Duration(delay)
+}
+
+// Timeout returns how long MaxAttempts polls and the waits after each of
+// them take, which is the timeout of operations that do not set their own.
+func (p PollPolicy) Timeout() time.Duration {
+	var timeout time.Duration
+	for poll := 1; poll <= p.MaxAttempts; poll++ {
+		timeout += p.Delay(poll)
+	}
+	return timeout
+}
+
+// Waiter waits for asynchronous guku API operations. It is shared by all
+// resources of a provider.
+type Waiter struct {
+	Clock  Clock
+	Policy PollPolicy
+}
+
+// PollUntil calls check until it reports done, waiting between calls as set
+// by the policy, and returns false without an error once timeout passed.
+// The time passed is the longer of the time on the clock and the time this
+// wait slept, so that waits on a clock that does not advance while sleeping
+// still end, without counting the sleeps of waits running in parallel. check
+// is called with a context that is done at the deadline, so that guku API
+// requests cannot outlast it either.
+func (w *Waiter) PollUntil(ctx context.Context, timeout time.Duration, check func(ctx context.Context, poll int) (bool, error)) (bool, error) {
+	start := w.Clock.Now()
+	var slept time.Duration
+	remaining := func() time.Duration {
+		elapsed := w.Clock.Now().Sub(start)
+		if elapsed < slept {
+			elapsed = slept
+		}
+		return timeout - elapsed
+	}
+
+	for poll := 1; ; poll++ {
+		left := remaining()
+		if left <= 0 {
+			return false, nil
+		}
+
+		done, err := w.check(ctx, left, poll, check)
+		if err != nil || done {
+			return done, err
+		}
+
+		delay := w.Policy.Delay(poll)
+		if left := remaining(); left <= 0 {
+			return false, nil
+		} else if delay > left {
+			delay = left
+		}
+		if err := w.Clock.Sleep(ctx, delay); err != nil {
+			return false, err
+		}
+		slept += delay
+	}
+}
+
+// check calls check with a context that is done after remaining. A check
+// that fails because the deadline passed is not done, rather than an error.
+func (w *Waiter) check(ctx context.Context, remaining time.Duration, poll int, check func(ctx context.Context, poll int) (bool, error)) (bool, error) {
+	checkCtx, cancel := context.WithTimeout(ctx, remaining)
+	defer cancel()
+
+	done, err := check(checkCtx, poll)
+	if err != nil && ctx.Err() == nil && errors.Is(checkCtx.Err(), context.DeadlineExceeded) {
+		return false, nil
+	}
+	return done, err
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPollPolicyDelay(t *testing.T) {
+	policy := PollPolicy{Interval: time.Second, MaxInterval: 5 * time.Second, Backoff: 2}
+
+	for poll, expected := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 5 * time.Second,
+		9: 5 * time.Second,
+	} {
+		if delay := policy.Delay(poll); delay != expected {
+			t.Errorf("poll %d: expected %s, got %s", poll, expected, delay)
+		}
+	}
+
+	policy.Backoff = 1
+	if delay := policy.Delay(9); delay != time.Second {
+		t.Errorf("expected fixed interval, got %s", delay)
+	}
+}
+
+func TestPollPolicyTimeout(t *testing.T) {
+	policy := PollPolicy{MaxAttempts: 5, Interval: time.Second, MaxInterval: 5 * time.Second, Backoff: 2}
+	// waits of 1s, 2s, 4s, 5s and 5s after the 5 polls
+	if timeout := policy.Timeout(); timeout != 17*time.Second {
+		t.Errorf("expected 17s, got %s", timeout)
+	}
+
+	if timeout := DefaultPollPolicy.Timeout(); timeout != 20*DefaultPollPolicy.Interval {
+		t.Errorf("expected 20 intervals, got %s", timeout)
+	}
+}
+
+func TestWaiterPollUntil_done(t *testing.T) {
+	clock := &fakeClock{}
+	waiter := &Waiter{Clock: clock, Policy: PollPolicy{MaxAttempts: 5, Interval: time.Second, MaxInterval: time.Minute, Backoff: 2}}
+
+	done, err := waiter.PollUntil(context.Background(), time.Minute, func(ctx context.Context, poll int) (bool, error) {
+		return poll == 3, nil
+	})
+	if !done || err != nil {
+		t.Fatalf("expected done, got %t, %v", done, err)
+	}
+	if clock.Count(time.Second) != 1 || clock.Count(2*time.Second) != 1 {
+		t.Errorf("expected waits of 1s and 2s, got %v", clock.slept)
+	}
+}
+
+func TestWaiterPollUntil_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	waiter := &Waiter{Clock: &fakeClock{}, Policy: DefaultPollPolicy}
+	_, err := waiter.PollUntil(ctx, time.Minute, func(ctx context.Context, poll int) (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWaiterPollUntil_timeout(t *testing.T) {
+	clock := &fakeClock{}
+	waiter := &Waiter{Clock: clock, Policy: PollPolicy{MaxAttempts: 1, Interval: 2 * time.Second, MaxInterval: time.Minute, Backoff: 1}}
+
+	polls := 0
+	done, err := waiter.PollUntil(context.Background(), 5*time.Second, func(ctx context.Context, poll int) (bool, error) {
+		polls++
+		return false, nil
+	})
+	if done || err != nil {
+		t.Fatalf("expected not done without error, got %t, %v", done, err)
+	}
+	// the last wait is cut short at the deadline, after which there are no
+	// more polls
+	if polls != 3 || clock.Count(2*time.Second) != 2 || clock.Count(time.Second) != 1 {
+		t.Errorf("expected 3 polls and waits of 2s, 2s and 1s, got %d and %v", polls, clock.slept)
+	}
+}
+
+func TestWaiterPollUntil_slowCheck(t *testing.T) {
+	waiter := &Waiter{Clock: realClock{}, Policy: DefaultPollPolicy}
+
+	// a check blocked on the guku API is cancelled at the deadline
+	done, err := waiter.PollUntil(context.Background(), 50*time.Millisecond, func(ctx context.Context, poll int) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	})
+	if done || err != nil {
+		t.Fatalf("expected not done without error, got %t, %v", done, err)
+	}
+}
+
+func TestWaiterPollUntil_parallel(t *testing.T) {
+	waiter := &Waiter{Clock: instantClock{}, Policy: DefaultPollPolicy}
+
+	// waits sharing the provider's clock each poll until their own timeout
+	var wg sync.WaitGroup
+	polls := make([]int, 4)
+	for i := range polls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			waiter.PollUntil(context.Background(), DefaultPollPolicy.Timeout(), func(ctx context.Context, poll int) (bool, error) {
+				polls[i] = poll
+				return false, nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, count := range polls {
+		if count != DefaultPollPolicy.MaxAttempts {
+			t.Errorf("expected wait %d to poll %d times, got %d", i, DefaultPollPolicy.MaxAttempts, count)
+		}
+	}
+}